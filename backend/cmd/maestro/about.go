@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"jazz/backend/configs"
+)
+
+func init() {
+	register(command{
+		name:        "about",
+		usage:       "about [--json]",
+		description: "Display basic information about the application and its configuration",
+		run:         about,
+	})
+}
+
+// aboutSection is a titled group of facts printed by the about command.
+type aboutSection struct {
+	Title string      `json:"title"`
+	Items []aboutItem `json:"items"`
+}
+
+// aboutItem is a single fact printed by the about command.
+type aboutItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// about prints the environment, the files the configuration was loaded
+// from and the active drivers.
+func about(args []string) error {
+	section, asJSON, err := parseShowArgs(args)
+	if err != nil {
+		return err
+	}
+	if section != "" {
+		return fmt.Errorf("unexpected argument %q", section)
+	}
+
+	cfg := configs.Current()
+	configFiles, err := configs.ConfigFiles()
+	if err != nil {
+		return err
+	}
+
+	cache := "not cached"
+	if configs.IsCached() {
+		cache = "cached at " + configs.CachePath()
+	}
+
+	sections := []aboutSection{
+		{Title: "Environment", Items: []aboutItem{
+			{"Application Name", cfg.App.Name},
+			{"Environment", cfg.App.Env},
+			{"Debug Mode", enabled(cfg.App.Debug)},
+			{"URL", cfg.App.URL},
+			{"Timezone", cfg.App.Timezone},
+			{"Locale", cfg.App.Locale},
+			{"Go Version", runtime.Version()},
+			{"Project Root", configs.ProjectRoot()},
+		}},
+		{Title: "Configuration", Items: []aboutItem{
+			{"Environment Files", list(configs.EnvFiles())},
+			{"Config Files", list(configFiles)},
+			{"Config Cache", cache},
+			{"Hot Reload", enabled(cfg.App.ConfigWatch)},
+		}},
+		{Title: "Drivers", Items: []aboutItem{
+			{"Cache", cfg.Cache.Default},
+			{"Database", cfg.Database.Default},
+			{"Maintenance", cfg.App.Maintenance.Driver},
+		}},
+	}
+
+	if asJSON {
+		return printJSON(sections)
+	}
+
+	width := 0
+	for _, s := range sections {
+		for _, item := range s.Items {
+			width = max(width, len(item.Name))
+		}
+	}
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf(" %s\n", s.Title)
+		for _, item := range s.Items {
+			fmt.Printf("  %-*s  %s\n", width, item.Name, item.Value)
+		}
+	}
+	return nil
+}
+
+// enabled formats a boolean setting.
+func enabled(on bool) string {
+	if on {
+		return "ENABLED"
+	}
+	return "OFF"
+}
+
+// list formats a list of files, or "none" when it is empty.
+func list(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"jazz/backend/configs"
+)
+
+func init() {
+	register(command{
+		name:        "config:cache",
+		usage:       "config:cache",
+		description: "Create a cache file for faster configuration loading",
+		run:         configCache,
+	})
+	register(command{
+		name:        "config:clear",
+		usage:       "config:clear",
+		description: "Remove the configuration cache file",
+		run:         configClear,
+	})
+	register(command{
+		name:        "config:show",
+		usage:       "config:show [section] [--json]",
+		description: "Display the effective configuration and where each value came from",
+		run:         configShow,
+	})
+}
+
+// configCache resolves the configuration once and writes it to the cache file.
+func configCache(args []string) error {
+	if err := configs.ClearCache(); err != nil {
+		return err
+	}
+
+	path, err := configs.WriteCache()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Configuration cached successfully at %s.\n", path)
+	return nil
+}
+
+// configClear removes the configuration cache file.
+func configClear(args []string) error {
+	if err := configs.ClearCache(); err != nil {
+		return err
+	}
+
+	fmt.Println("Configuration cache cleared successfully.")
+	return nil
+}
+
+// configShow prints the effective configuration, with secrets redacted.
+func configShow(args []string) error {
+	section, asJSON, err := parseShowArgs(args)
+	if err != nil {
+		return err
+	}
+
+	entries, err := configs.Describe(section)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(entries)
+	}
+
+	keyWidth, valueWidth := 0, 0
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = fmt.Sprint(entry.Value)
+		keyWidth = max(keyWidth, len(entry.Key))
+		valueWidth = max(valueWidth, len(values[i]))
+	}
+	for i, entry := range entries {
+		fmt.Printf("  %-*s  %-*s  %s\n", keyWidth, entry.Key, valueWidth, values[i], entry.Source)
+	}
+	return nil
+}
+
+// parseShowArgs reads the optional section name and the --json flag.
+func parseShowArgs(args []string) (string, bool, error) {
+	section, asJSON := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--json":
+			asJSON = true
+		case strings.HasPrefix(arg, "-"):
+			return "", false, fmt.Errorf("unknown option %q", arg)
+		case section != "":
+			return "", false, fmt.Errorf("unexpected argument %q", arg)
+		default:
+			section = arg
+		}
+	}
+	return section, asJSON, nil
+}
+
+// printJSON writes value to stdout as indented JSON.
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
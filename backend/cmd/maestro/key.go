@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/encryption"
+)
+
+func init() {
+	register(command{
+		name:        "key:generate",
+		usage:       "key:generate [--show] [--force]",
+		description: "Set the application key",
+		run:         keyGenerate,
+	})
+}
+
+// keyGenerate creates a key for the configured cipher and writes it to the
+// .env file APP_KEY is read from, moving the replaced key to
+// APP_PREVIOUS_KEYS. --show only prints it; --force is required in production.
+func keyGenerate(args []string) error {
+	show, force := false, false
+	for _, arg := range args {
+		switch arg {
+		case "--show":
+			show = true
+		case "--force":
+			force = true
+		default:
+			return fmt.Errorf("unknown option %q", arg)
+		}
+	}
+
+	// Read the settings without validating the configuration, which fails
+	// on the missing or invalid key this command is meant to replace.
+	cipher, err := configs.Unvalidated("app.cipher")
+	if err != nil {
+		return err
+	}
+	key, err := encryption.GenerateKey(cipher)
+	if err != nil {
+		return err
+	}
+	formatted := encryption.FormatKey(key)
+
+	if show {
+		fmt.Println(formatted)
+		return nil
+	}
+
+	env, err := configs.Unvalidated("app.env")
+	if err != nil {
+		return err
+	}
+	if env == "production" && !force {
+		return fmt.Errorf("the application is in production, use --force to replace the key")
+	}
+
+	// Write each variable to the .env file that wins for it, so an overlay
+	// such as .env.local cannot silently override the new key.
+	keyPath, err := configs.EnvFileFor("APP_KEY")
+	if err != nil {
+		return err
+	}
+	writes := map[string][][2]string{keyPath: {{"APP_KEY", formatted}}}
+
+	// Keep the replaced key around so existing values can still be decrypted.
+	previous := configs.Get("APP_KEY").(string)
+	if previous != "" {
+		previousPath, err := configs.EnvFileFor("APP_PREVIOUS_KEYS")
+		if err != nil {
+			return err
+		}
+		keys := prependKey(previous, configs.Get("APP_PREVIOUS_KEYS").(string))
+		writes[previousPath] = append(writes[previousPath], [2]string{"APP_PREVIOUS_KEYS", keys})
+	}
+
+	for path, values := range writes {
+		if err := writeEnvValues(path, values...); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Application key set successfully in %s.\n", keyPath)
+	if previous != "" {
+		fmt.Println("The previous key was added to APP_PREVIOUS_KEYS to keep decrypting existing values.")
+	}
+	return nil
+}
+
+// prependKey puts key first in a comma-separated list of keys, once.
+func prependKey(key, list string) string {
+	keys := []string{key}
+	for _, k := range strings.Split(list, ",") {
+		if k = strings.TrimSpace(k); k != "" && k != key {
+			keys = append(keys, k)
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// writeEnvValues replaces the assignments of the given keys in an .env file,
+// or appends them, keeping the rest of the file and its permissions as they are.
+func writeEnvValues(path string, values ...[2]string) error {
+	mode := os.FileMode(0644)
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	updated := string(content)
+	for _, value := range values {
+		key := value[0]
+		assignment := regexp.MustCompile(`(?m)^(export\s+)?` + regexp.QuoteMeta(key) + `=.*$`)
+		line := key + "=" + value[1]
+		if assignment.MatchString(updated) {
+			updated = assignment.ReplaceAllLiteralString(updated, line)
+		} else {
+			if updated != "" && !strings.HasSuffix(updated, "\n") {
+				updated += "\n"
+			}
+			updated += line + "\n"
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
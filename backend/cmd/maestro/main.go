@@ -0,0 +1,67 @@
+// maestro is the Jazz command-line tool, similar to Laravel's Artisan.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command is a Maestro command such as "config:cache".
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var commands = map[string]command{}
+
+// register makes a command available on the command line.
+func register(cmd command) {
+	commands[cmd.name] = cmd
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "list" || os.Args[1] == "help" || os.Args[1] == "--help" {
+		printCommands()
+		return
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Command %q is not defined.\n\n", os.Args[1])
+		printCommands()
+		os.Exit(1)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+// printCommands lists the available commands grouped by namespace.
+func printCommands() {
+	names := make([]string, 0, len(commands))
+	width := 0
+	for name, cmd := range commands {
+		names = append(names, name)
+		if len(cmd.usage) > width {
+			width = len(cmd.usage)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: maestro <command> [arguments]")
+	namespace := ""
+	for _, name := range names {
+		if ns, _, found := strings.Cut(name, ":"); found && ns != namespace {
+			namespace = ns
+			fmt.Printf("\n %s\n", namespace)
+		}
+		cmd := commands[name]
+		fmt.Printf("  %-*s  %s\n", width, cmd.usage, cmd.description)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/migration"
+
+	// Registers the application's migrations
+	_ "jazz/backend/database/migrations"
+)
+
+func init() {
+	register(command{
+		name:        "migrate",
+		usage:       "migrate [--database=name] [--step] [--seed] [--force]",
+		description: "Run the database migrations",
+		run:         migrate,
+	})
+	register(command{
+		name:        "migrate:rollback",
+		usage:       "migrate:rollback [--database=name] [--step=n] [--force]",
+		description: "Rollback the last database migration batch, or the last n migrations",
+		run:         migrateRollback,
+	})
+	register(command{
+		name:        "migrate:reset",
+		usage:       "migrate:reset [--database=name] [--force]",
+		description: "Rollback all database migrations",
+		run:         migrateReset,
+	})
+	register(command{
+		name:        "migrate:refresh",
+		usage:       "migrate:refresh [--database=name] [--seed] [--force]",
+		description: "Reset and re-run all migrations",
+		run:         migrateRefresh,
+	})
+	register(command{
+		name:        "migrate:fresh",
+		usage:       "migrate:fresh [--database=name] [--seed] [--force]",
+		description: "Drop all tables and re-run all migrations",
+		run:         migrateFresh,
+	})
+	register(command{
+		name:        "migrate:status",
+		usage:       "migrate:status [--database=name] [--json]",
+		description: "Show the status of each migration",
+		run:         migrateStatus,
+	})
+}
+
+// migrateOptions are the options shared by the migrate commands.
+type migrateOptions struct {
+	database string
+	step     int
+	seed     bool
+	force    bool
+	asJSON   bool
+}
+
+// parseMigrateOptions parses --database=name, --step[=n], --seed, --force and --json.
+func parseMigrateOptions(args []string) (migrateOptions, error) {
+	var opts migrateOptions
+	for _, arg := range args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch {
+		case name == "--database" && hasValue && value != "":
+			opts.database = value
+		case name == "--step" && !hasValue:
+			opts.step = 1
+		case name == "--step" && hasValue:
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return opts, fmt.Errorf("--step must be a positive number, got %q", value)
+			}
+			opts.step = n
+		case arg == "--seed":
+			opts.seed = true
+		case arg == "--force":
+			opts.force = true
+		case arg == "--json":
+			opts.asJSON = true
+		default:
+			return opts, fmt.Errorf("unknown option %q", arg)
+		}
+	}
+	return opts, nil
+}
+
+// migrator parses the options and opens the migrator. Commands changing the
+// schema require --force in production.
+func migrator(args []string, changesSchema bool) (*migration.Migrator, migrateOptions, error) {
+	opts, err := parseMigrateOptions(args)
+	if err != nil {
+		return nil, opts, err
+	}
+	if changesSchema && configs.App().Env == "production" && !opts.force {
+		return nil, opts, fmt.Errorf("the application is in production, use --force to run this command")
+	}
+
+	m, err := migration.ForConnection(opts.database)
+	return m, opts, err
+}
+
+// migrate runs the pending migrations.
+func migrate(args []string) error {
+	m, opts, err := migrator(args, true)
+	if err != nil {
+		return err
+	}
+	names, err := m.Migrate(opts.step > 0)
+	printMigrations("Ran", names, "Nothing to migrate.")
+	return seedAfter(opts, err)
+}
+
+// migrateRollback reverts the last batch, or the last --step=n migrations.
+func migrateRollback(args []string) error {
+	m, opts, err := migrator(args, true)
+	if err != nil {
+		return err
+	}
+	names, err := m.Rollback(opts.step)
+	printMigrations("Rolled back", names, "Nothing to rollback.")
+	return err
+}
+
+// migrateReset reverts every migration.
+func migrateReset(args []string) error {
+	m, _, err := migrator(args, true)
+	if err != nil {
+		return err
+	}
+	names, err := m.Reset()
+	printMigrations("Rolled back", names, "Nothing to rollback.")
+	return err
+}
+
+// migrateRefresh reverts every migration and runs them again.
+func migrateRefresh(args []string) error {
+	m, opts, err := migrator(args, true)
+	if err != nil {
+		return err
+	}
+	names, err := m.Refresh()
+	printMigrations("Ran", names, "Nothing to migrate.")
+	return seedAfter(opts, err)
+}
+
+// migrateFresh drops every table and runs the migrations again.
+func migrateFresh(args []string) error {
+	m, opts, err := migrator(args, true)
+	if err != nil {
+		return err
+	}
+	if err := m.DropAllTables(); err != nil {
+		return err
+	}
+	fmt.Println("Dropped all tables successfully.")
+
+	names, err := m.Migrate(false)
+	printMigrations("Ran", names, "Nothing to migrate.")
+	return seedAfter(opts, err)
+}
+
+// seedAfter runs DatabaseSeeder once the migrations succeeded, with --seed.
+func seedAfter(opts migrateOptions, err error) error {
+	if err != nil || !opts.seed {
+		return err
+	}
+	return seed(opts.database, "DatabaseSeeder")
+}
+
+// migrateStatus lists the migrations and whether they ran.
+func migrateStatus(args []string) error {
+	m, opts, err := migrator(args, false)
+	if err != nil {
+		return err
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	if opts.asJSON {
+		return printJSON(statuses)
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No migrations found.")
+		return nil
+	}
+
+	width := 0
+	for _, s := range statuses {
+		width = max(width, len(s.Name))
+	}
+	fmt.Printf("  %-*s  %s\n", width, "Migration name", "Batch / Status")
+	for _, s := range statuses {
+		status := "Pending"
+		if s.Ran {
+			status = fmt.Sprintf("[%d] Ran", s.Batch)
+		}
+		fmt.Printf("  %-*s  %s\n", width, s.Name, status)
+	}
+	return nil
+}
+
+// printMigrations prints the migrations a command went through.
+func printMigrations(verb string, names []string, none string) {
+	if len(names) == 0 {
+		fmt.Println(none)
+		return
+	}
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", verb, name)
+	}
+}
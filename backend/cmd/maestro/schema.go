@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/migration"
+)
+
+func init() {
+	register(command{
+		name:        "schema:dump",
+		usage:       "schema:dump [--database=name] [--prune]",
+		description: "Dump the database schema to database/schema, optionally pruning the migrations it contains",
+		run:         schemaDump,
+	})
+}
+
+// schemaDump writes the schema of a connection to database/schema/{connection}.sql.
+func schemaDump(args []string) error {
+	connection, prune := "", false
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch {
+		case name == "--database" && value != "":
+			connection = value
+		case arg == "--prune":
+			prune = true
+		default:
+			return fmt.Errorf("unknown option %q", arg)
+		}
+	}
+
+	m, err := migration.ForConnection(connection)
+	if err != nil {
+		return err
+	}
+	path, err := m.DumpSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Database schema dumped to %s.\n", path)
+
+	if !prune {
+		return nil
+	}
+	pruned, err := m.PruneMigrations(configs.BasePath("backend", "database", "migrations"))
+	printMigrations("Pruned", pruned, "No migrations to prune.")
+	return err
+}
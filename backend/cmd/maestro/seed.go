@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"jazz/backend/configs"
+	"jazz/backend/database/seeders"
+	"jazz/backend/pkg/database"
+)
+
+func init() {
+	register(command{
+		name:        "db:seed",
+		usage:       "db:seed [--class=name] [--database=name] [--force]",
+		description: "Seed the database with records",
+		run:         dbSeed,
+	})
+}
+
+// dbSeed runs DatabaseSeeder, or the seeder given with --class.
+func dbSeed(args []string) error {
+	class, connection, force := "DatabaseSeeder", "", false
+	for _, arg := range args {
+		name, value, _ := strings.Cut(arg, "=")
+		switch {
+		case name == "--class" && value != "":
+			class = value
+		case name == "--database" && value != "":
+			connection = value
+		case arg == "--force":
+			force = true
+		default:
+			return fmt.Errorf("unknown option %q", arg)
+		}
+	}
+	if configs.App().Env == "production" && !force {
+		return fmt.Errorf("the application is in production, use --force to run this command")
+	}
+
+	return seed(connection, class)
+}
+
+// seed runs a registered seeder on a connection, the default one when empty.
+func seed(connection, class string) error {
+	seeder, err := seeders.Find(class)
+	if err != nil {
+		return err
+	}
+	if connection == "" {
+		connection = configs.Database().Default
+	}
+	db, err := database.Connection(connection)
+	if err != nil {
+		return err
+	}
+
+	if err := seeders.Call(db, seeder); err != nil {
+		return err
+	}
+	fmt.Println("Database seeding completed successfully.")
+	return nil
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"jazz/backend/configs"
 	"jazz/backend/pkg/cache"
@@ -9,6 +10,7 @@ import (
 	"jazz/backend/pkg/logger"
 	"jazz/backend/routes"
 	"net/http"
+	"os"
 
 	"gorm.io/gorm"
 )
@@ -20,13 +22,22 @@ func main() {
 	// Usa o logger global para logar informações iniciais da aplicação
 	logger.Logger.Info("Application has started")
 
-	// Carrega as configurações
-	if err := configs.LoadConfig(); err != nil {
+	// Carrega e valida as configurações
+	if _, err := configs.Load(); err != nil {
 		panic(fmt.Sprintf("Error loading config: %v", err))
 	}
 
-	// Inicializa o banco de dados (Singleton)
-	db := database.InitializeDatabase()
+	// Recarrega as configurações quando os arquivos mudam ou com SIGHUP
+	if app := configs.App(); app.ConfigWatch {
+		go configs.Watch(context.Background(), app.ConfigWatchInterval)
+	}
+
+	// Inicializa o banco de dados, aguardando até o connect_timeout
+	db, err := database.DefaultConnection()
+	if err != nil {
+		logger.Logger.Errorw("Database is not available", "error", err)
+		os.Exit(1)
+	}
 
 	// Inicializa o cache (Singleton)
 	cacheManager := cache.NewCacheManager()
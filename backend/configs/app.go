@@ -1,22 +1,56 @@
 package configs
 
+import "time"
+
 // GetAppConfig returns application configurations as a map, similar to Laravel's configuration files.
 func GetAppConfig() map[string]interface{} {
+	return appDefinition(processEnv{})
+}
+
+// appDefinition builds the application configuration from the variables env reads.
+func appDefinition(env envReader) map[string]interface{} {
 	return map[string]interface{}{
-		"name":            GetWithDefault("APP_NAME", "JazzApp"),
-		"env":             GetWithDefault("APP_ENV", "production"),
-		"debug":           GetWithDefault("APP_DEBUG", false),
-		"url":             GetWithDefault("APP_URL", "http://localhost"),
-		"timezone":        GetWithDefault("APP_TIMEZONE", "UTC"),
-		"locale":          GetWithDefault("APP_LOCALE", "en"),
-		"fallback_locale": GetWithDefault("APP_FALLBACK_LOCALE", "en"),
-		"faker_locale":    GetWithDefault("APP_FAKER_LOCALE", "en_US"),
-		"cipher":          GetWithDefault("APP_CIPHER", "AES-256-CBC"),
-		"key":             Get("APP_KEY"),
-		"previous_keys":   GetWithDefault("APP_PREVIOUS_KEYS", ","),
+		"name":                  env.GetWithDefault("APP_NAME", "JazzApp"),
+		"env":                   env.GetWithDefault("APP_ENV", "production"),
+		"debug":                 env.GetWithDefault("APP_DEBUG", false),
+		"url":                   env.GetWithDefault("APP_URL", "http://localhost"),
+		"timezone":              env.GetWithDefault("APP_TIMEZONE", "UTC"),
+		"locale":                env.GetWithDefault("APP_LOCALE", "en"),
+		"fallback_locale":       env.GetWithDefault("APP_FALLBACK_LOCALE", "en"),
+		"faker_locale":          env.GetWithDefault("APP_FAKER_LOCALE", "en_US"),
+		"cipher":                env.GetWithDefault("APP_CIPHER", "AES-256-CBC"),
+		"key":                   env.Get("APP_KEY"),
+		"previous_keys":         env.GetWithDefault("APP_PREVIOUS_KEYS", ","),
+		"config_watch":          env.GetWithDefault("APP_CONFIG_WATCH", false),
+		"config_watch_interval": env.GetWithDefault("APP_CONFIG_WATCH_INTERVAL", "2s"),
 		"maintenance": map[string]interface{}{
-			"driver": GetWithDefault("APP_MAINTENANCE_DRIVER", "file"),
-			"store":  GetWithDefault("APP_MAINTENANCE_STORE", "database"),
+			"driver": env.GetWithDefault("APP_MAINTENANCE_DRIVER", "file"),
+			"store":  env.GetWithDefault("APP_MAINTENANCE_STORE", "database"),
 		},
 	}
 }
+
+// AppConfig holds the typed application settings.
+type AppConfig struct {
+	Name           string            `config:"name"`
+	Env            string            `config:"env"`
+	Debug          bool              `config:"debug"`
+	URL            string            `config:"url"`
+	Timezone       string            `config:"timezone"`
+	Locale         string            `config:"locale"`
+	FallbackLocale string            `config:"fallback_locale"`
+	FakerLocale    string            `config:"faker_locale"`
+	Cipher         string            `config:"cipher"`
+	Key            string            `config:"key"`
+	PreviousKeys   []string          `config:"previous_keys"`
+	Maintenance    MaintenanceConfig `config:"maintenance"`
+	// ConfigWatch reloads the configuration when its files change.
+	ConfigWatch         bool          `config:"config_watch"`
+	ConfigWatchInterval time.Duration `config:"config_watch_interval"`
+}
+
+// MaintenanceConfig holds the maintenance mode settings.
+type MaintenanceConfig struct {
+	Driver string `config:"driver"`
+	Store  string `config:"store"`
+}
@@ -4,8 +4,13 @@ import "strings"
 
 // GetCacheConfig returns cache configurations as a map, similar to Laravel's cache configuration files.
 func GetCacheConfig() map[string]interface{} {
+	return cacheDefinition(processEnv{})
+}
+
+// cacheDefinition builds the cache configuration from the variables env reads.
+func cacheDefinition(env envReader) map[string]interface{} {
 	return map[string]interface{}{
-		"default": GetWithDefault("CACHE_STORE", "database"),
+		"default": env.GetWithDefault("CACHE_STORE", env.GetWithDefault("CACHE_DRIVER", "database")),
 		"stores": map[string]interface{}{
 			"array": map[string]interface{}{
 				"driver":    "array",
@@ -13,50 +18,85 @@ func GetCacheConfig() map[string]interface{} {
 			},
 			"database": map[string]interface{}{
 				"driver":          "database",
-				"connection":      Get("DB_CACHE_CONNECTION"),
-				"table":           GetWithDefault("DB_CACHE_TABLE", "cache"),
-				"lock_connection": Get("DB_CACHE_LOCK_CONNECTION"),
-				"lock_table":      Get("DB_CACHE_LOCK_TABLE"),
+				"connection":      env.Get("DB_CACHE_CONNECTION"),
+				"table":           env.GetWithDefault("DB_CACHE_TABLE", "cache"),
+				"lock_connection": env.Get("DB_CACHE_LOCK_CONNECTION"),
+				"lock_table":      env.Get("DB_CACHE_LOCK_TABLE"),
 			},
 			"file": map[string]interface{}{
 				"driver":    "file",
-				"path":      GetWithDefault("CACHE_FILE_PATH", "storage/framework/cache/data"),
-				"lock_path": GetWithDefault("CACHE_LOCK_PATH", "storage/framework/cache/data"),
+				"path":      env.GetWithDefault("CACHE_FILE_PATH", "storage/framework/cache/data"),
+				"lock_path": env.GetWithDefault("CACHE_LOCK_PATH", "storage/framework/cache/data"),
 			},
 			"memcached": map[string]interface{}{
 				"driver":        "memcached",
-				"persistent_id": Get("MEMCACHED_PERSISTENT_ID"),
+				"persistent_id": env.Get("MEMCACHED_PERSISTENT_ID"),
 				"sasl": []string{
-					Get("MEMCACHED_USERNAME").(string),
-					Get("MEMCACHED_PASSWORD").(string),
+					toString(env.Get("MEMCACHED_USERNAME")),
+					toString(env.Get("MEMCACHED_PASSWORD")),
 				},
 				"options": map[string]interface{}{},
 				"servers": []map[string]interface{}{
 					{
-						"host":   GetWithDefault("MEMCACHED_HOST", "127.0.0.1"),
-						"port":   GetWithDefault("MEMCACHED_PORT", 11211),
+						"host":   env.GetWithDefault("MEMCACHED_HOST", "127.0.0.1"),
+						"port":   env.GetWithDefault("MEMCACHED_PORT", 11211),
 						"weight": 100,
 					},
 				},
 			},
 			"redis": map[string]interface{}{
 				"driver":          "redis",
-				"connection":      GetWithDefault("REDIS_CACHE_CONNECTION", "cache"),
-				"lock_connection": GetWithDefault("REDIS_CACHE_LOCK_CONNECTION", "default"),
-				"url":             GetWithDefault("REDIS_URL", "redis://localhost:6379"),
+				"connection":      env.GetWithDefault("REDIS_CACHE_CONNECTION", "cache"),
+				"lock_connection": env.GetWithDefault("REDIS_CACHE_LOCK_CONNECTION", "default"),
+				"url":             env.GetWithDefault("REDIS_URL", "redis://localhost:6379"),
 			},
 			"dynamodb": map[string]interface{}{
 				"driver":   "dynamodb",
-				"key":      Get("AWS_ACCESS_KEY_ID"),
-				"secret":   Get("AWS_SECRET_ACCESS_KEY"),
-				"region":   GetWithDefault("AWS_DEFAULT_REGION", "us-east-1"),
-				"table":    GetWithDefault("DYNAMODB_CACHE_TABLE", "cache"),
-				"endpoint": Get("DYNAMODB_ENDPOINT"),
+				"key":      env.Get("AWS_ACCESS_KEY_ID"),
+				"secret":   env.Get("AWS_SECRET_ACCESS_KEY"),
+				"region":   env.GetWithDefault("AWS_DEFAULT_REGION", "us-east-1"),
+				"table":    env.GetWithDefault("DYNAMODB_CACHE_TABLE", "cache"),
+				"endpoint": env.Get("DYNAMODB_ENDPOINT"),
 			},
 			"swing": map[string]interface{}{
 				"driver": "swing",
 			},
 		},
-		"prefix": strings.ToLower(strings.ReplaceAll(GetWithDefault("CACHE_PREFIX", Get("APP_NAME").(string)+"_cache_").(string), " ", "_")),
+		"prefix": strings.ToLower(strings.ReplaceAll(toString(env.GetWithDefault("CACHE_PREFIX", toString(env.Get("APP_NAME"))+"_cache_")), " ", "_")),
 	}
 }
+
+// CacheConfig holds the typed cache settings.
+type CacheConfig struct {
+	Default string                      `config:"default"`
+	Stores  map[string]CacheStoreConfig `config:"stores"`
+	Prefix  string                      `config:"prefix"`
+}
+
+// CacheStoreConfig holds the settings of a cache store. Each driver only uses the fields it needs.
+type CacheStoreConfig struct {
+	Driver         string                 `config:"driver"`
+	Serialize      bool                   `config:"serialize"`
+	Connection     string                 `config:"connection"`
+	Table          string                 `config:"table"`
+	LockConnection string                 `config:"lock_connection"`
+	LockTable      string                 `config:"lock_table"`
+	Path           string                 `config:"path"`
+	LockPath       string                 `config:"lock_path"`
+	PersistentID   string                 `config:"persistent_id"`
+	SASL           []string               `config:"sasl"`
+	Options        map[string]interface{} `config:"options"`
+	Servers        []MemcachedServer      `config:"servers"`
+	URL            string                 `config:"url"`
+	Key            string                 `config:"key"`
+	Secret         string                 `config:"secret"`
+	Region         string                 `config:"region"`
+	Endpoint       string                 `config:"endpoint"`
+}
+
+// MemcachedServer is a single server of a memcached store.
+type MemcachedServer struct {
+	Host   string `config:"host"`
+	Port   int    `config:"port"`
+	Weight int    `config:"weight"`
+}
@@ -0,0 +1,193 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePath returns the file the resolved configuration is cached in:
+// JAZZ_CONFIG_CACHE when set, otherwise bootstrap/cache/config.json.
+func CachePath() string {
+	if path := os.Getenv("JAZZ_CONFIG_CACHE"); path != "" {
+		return path
+	}
+	return BasePath("bootstrap", "cache", "config.json")
+}
+
+// IsCached reports whether a configuration cache file exists.
+func IsCached() bool {
+	_, err := os.Stat(CachePath())
+	return err == nil
+}
+
+// WriteCache resolves the configuration from the .env and config files,
+// ignoring any existing cache, and writes it to CachePath. Secret references
+// are resolved to validate the configuration but written as references, and
+// variables read from a KEY_FILE are written as secret://env/KEY references,
+// so neither ends up on disk. Variables set directly in the environment or a
+// .env file are written as they are.
+func WriteCache() (string, error) {
+	env := &cacheEnv{references: map[string]string{}}
+	tree, err := sourcesFrom(env, env.lookup)
+	if err != nil {
+		return "", err
+	}
+	if problems := env.embedded("", tree); len(problems) > 0 {
+		sort.Strings(problems)
+		return "", &ValidationError{Problems: problems}
+	}
+
+	tree, references, err := resolveSecrets(tree)
+	if err != nil {
+		return "", err
+	}
+
+	writeMu.Lock()
+	snap, err := resolve(tree)
+	writeMu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	for key, reference := range references {
+		setPath(snap.tree, key, reference)
+	}
+
+	// Indented output with sorted keys keeps the file diffable between deployments.
+	content, err := json.MarshalIndent(jsonable(snap.tree), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize configuration: %w", err)
+	}
+
+	path := CachePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create config cache directory: %w", err)
+	}
+
+	// Write to a temporary file first so a booting app never reads a partial cache.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(content, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to write config cache: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write config cache: %w", err)
+	}
+
+	return path, nil
+}
+
+// cacheEnv is the envReader WriteCache builds the configuration with. A
+// variable read from a KEY_FILE is returned as a secret://env/KEY reference.
+type cacheEnv struct {
+	// references maps every reference handed out to its variable.
+	references map[string]string
+}
+
+func (e *cacheEnv) Get(key string) interface{} {
+	return e.GetWithDefault(key, "")
+}
+
+func (e *cacheEnv) GetWithDefault(key string, defaultValue interface{}) interface{} {
+	LoadConfig()
+	if value, exists := e.lookup(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+func (e *cacheEnv) lookup(key string) (string, bool) {
+	if _, ok := secretFile(key); ok {
+		reference := secretScheme + "env/" + key
+		e.references[reference] = key
+		return reference, true
+	}
+	return lookupEnv(key)
+}
+
+// embedded reports the values below value that hold a reference within a
+// longer string, where it could not be resolved on boot.
+func (e *cacheEnv) embedded(key string, value interface{}) []string {
+	if m, ok := toMap(value); ok {
+		var problems []string
+		for k, v := range m {
+			problems = append(problems, e.embedded(join(key, k), v)...)
+		}
+		return problems
+	}
+
+	if s, ok := value.(string); ok {
+		if _, whole := e.references[s]; whole {
+			return nil
+		}
+		for reference, variable := range e.references {
+			if strings.Contains(s, reference) {
+				return []string{fmt.Sprintf("%s: %s_FILE can only be cached as a whole value", key, variable)}
+			}
+		}
+		return nil
+	}
+
+	if reflect.ValueOf(value).Kind() == reflect.Slice {
+		var problems []string
+		items, _ := toSlice(value)
+		for i, item := range items {
+			problems = append(problems, e.embedded(join(key, strconv.Itoa(i)), item)...)
+		}
+		return problems
+	}
+	return nil
+}
+
+// ClearCache removes the configuration cache file, if any.
+func ClearCache() error {
+	if err := os.Remove(CachePath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove config cache: %w", err)
+	}
+	return nil
+}
+
+// readCache loads the configuration tree from the cache file.
+func readCache() (map[string]interface{}, error) {
+	path := CachePath()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config cache %s: %w", path, err)
+	}
+
+	tree := map[string]interface{}{}
+	if err := json.Unmarshal(content, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse config cache %s: %w", path, err)
+	}
+	return tree, nil
+}
+
+// jsonable converts the values JSON cannot round-trip, such as durations, to strings.
+func jsonable(value interface{}) interface{} {
+	if m, ok := toMap(value); ok {
+		converted := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			converted[k] = jsonable(v)
+		}
+		return converted
+	}
+
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = jsonable(item)
+		}
+		return converted
+	}
+	return value
+}
@@ -2,69 +2,184 @@
 package configs
 
 import (
-	"fmt"
 	"jazz/backend/pkg/logger"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
-
-	"github.com/joho/godotenv"
 )
 
 var (
 	configValues map[string]interface{}
 	once         sync.Once
+
+	// exportedKeys are the variables LoadConfig set from .env files, and
+	// the values it set, as opposed to the ones that were already in the
+	// process environment or were set since.
+	exportedKeys = map[string]string{}
+	envMu        sync.RWMutex
+
+	// envValues are the .env values in effect, which Reload replaces without
+	// touching the process environment. envFiles are the .env files they
+	// were loaded from, and envOrigins the file of each value.
+	envValues  = map[string]string{}
+	envFiles   []string
+	envOrigins = map[string]string{}
 )
 
-// LoadConfig loads environment variables from the .env file using a Singleton pattern.
+// LoadConfig loads the .env files into the environment using a Singleton pattern.
+// The base .env file (JAZZ_ENV_FILE, or .env in the project root) is layered
+// with .env.{APP_ENV} and .env.local; variables already present in the process
+// environment always take precedence. Every file is optional.
 func LoadConfig() map[string]interface{} {
 	once.Do(func() {
 		// Initialize the logger first
 		logger.InitializeLogger()
 
-		// Get the current working directory
-		currentDir, err := os.Getwd()
+		entries, files, err := readEnvFiles()
 		if err != nil {
-			logger.Logger.Fatalw("Failed to get current working directory", "error", err)
+			logger.Logger.Fatalw("Failed to load .env file", "error", err)
 			return
 		}
 
-		// Construct the absolute path to the .env file
-		envPath := filepath.Join(currentDir, "../../../.env")
-
-		// Load the .env file
-		err = godotenv.Load(envPath)
-		if err != nil {
-			logger.Logger.Fatalw("Failed to load .env file", "path", envPath, "error", err)
-			return
+		if len(files) == 0 {
+			if os.Getenv("JAZZ_ENV_FILE") != "" {
+				logger.Logger.Warnw("Environment file set in JAZZ_ENV_FILE does not exist", "path", EnvFilePath())
+			} else if os.Getenv("APP_ENV") == "" {
+				logger.Logger.Warnw("No .env file found and APP_ENV is not set, using default configuration", "root", ProjectRoot())
+			}
 		}
 
-		// Confirm that the environment variables have been loaded by checking a known variable
-		requiredEnvVar := "APP_ENV"
-		if os.Getenv(requiredEnvVar) == "" {
-			logger.Logger.Warnw("Environment variable not loaded from .env file", "key", requiredEnvVar)
-			logger.Logger.Fatal(fmt.Errorf("environment variable %s not loaded from .env file", requiredEnvVar))
-			return
+		if len(files) > 0 {
+			logger.Logger.Infow("Loaded environment files", "files", files)
 		}
 
-		// Load environment variables into the configValues map
-		configValues = make(map[string]interface{})
-		for _, e := range os.Environ() {
-			pair := strings.SplitN(e, "=", 2)
-			if len(pair) == 2 {
-				configValues[pair[0]] = pair[1]
+		// Export the .env values without overriding the real environment
+		values := interpolate(entries)
+		envMu.Lock()
+		envFiles = files
+		for key, value := range values {
+			if _, exists := os.LookupEnv(key); exists {
+				continue
 			}
+			os.Setenv(key, value)
+			exportedKeys[key] = value
+			envValues[key] = value
+			envOrigins[key] = entries[key].file
 		}
+		envMu.Unlock()
+
+		configValues = environ()
 	})
 
 	return configValues
 }
 
+// environ returns the real process environment and the .env values in
+// effect as a map.
+func environ() map[string]interface{} {
+	values := make(map[string]interface{})
+	envMu.RLock()
+	for key, value := range envValues {
+		values[key] = value
+	}
+	envMu.RUnlock()
+
+	for _, e := range os.Environ() {
+		pair := strings.SplitN(e, "=", 2)
+		if len(pair) == 2 {
+			if _, ok := realEnv(pair[0]); ok {
+				values[pair[0]] = pair[1]
+			}
+		}
+	}
+	return values
+}
+
+// isExported reports whether a variable is set from a .env file.
+func isExported(key string) bool {
+	envMu.RLock()
+	defer envMu.RUnlock()
+	_, ok := envValues[key]
+	return ok
+}
+
+// realEnv returns a variable of the real process environment, leaving out
+// the ones still holding the value LoadConfig exported from a .env file.
+func realEnv(key string) (string, bool) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", false
+	}
+	envMu.RLock()
+	defer envMu.RUnlock()
+	if exported, ok := exportedKeys[key]; ok && exported == value {
+		return "", false
+	}
+	return value, true
+}
+
+// getenv returns a variable of the real process environment, or else its
+// .env value.
+func getenv(key string) (string, bool) {
+	if value, ok := realEnv(key); ok {
+		return value, true
+	}
+	envMu.RLock()
+	defer envMu.RUnlock()
+	value, ok := envValues[key]
+	return value, ok
+}
+
+// lookupEnv returns an environment variable, reading it from the file named by
+// KEY_FILE when KEY is unset. A KEY_FILE from the real environment also wins
+// over a KEY that only came from a .env file.
+func lookupEnv(key string) (string, bool) {
+	value, exists := getenv(key)
+
+	path, ok := secretFile(key)
+	if !ok {
+		return value, exists
+	}
+
+	secret, err := readSecretFile(resolvePath(path))
+	if err != nil {
+		logger.Logger.Errorw("Failed to read secret file", "key", key+"_FILE", "path", path, "error", err)
+		return value, exists
+	}
+	return secret, true
+}
+
+// secretFile returns the path in KEY_FILE when lookupEnv reads key from it.
+func secretFile(key string) (string, bool) {
+	_, exists := getenv(key)
+
+	fileKey := key + "_FILE"
+	path, hasFile := getenv(fileKey)
+	if !hasFile || path == "" || (exists && (!isExported(key) || isExported(fileKey))) {
+		return "", false
+	}
+	return path, true
+}
+
+// envReader reads the environment variables the configuration is built from.
+type envReader interface {
+	Get(key string) interface{}
+	GetWithDefault(key string, defaultValue interface{}) interface{}
+}
+
+// processEnv reads variables from the process environment.
+type processEnv struct{}
+
+func (processEnv) Get(key string) interface{} { return Get(key) }
+
+func (processEnv) GetWithDefault(key string, defaultValue interface{}) interface{} {
+	return GetWithDefault(key, defaultValue)
+}
+
 // Get returns the value of a specific environment variable.
 func Get(key string) interface{} {
-	configs := LoadConfig()
-	if value, exists := configs[key]; exists {
+	LoadConfig()
+	if value, exists := lookupEnv(key); exists {
 		return value
 	}
 	return ""
@@ -72,14 +187,23 @@ func Get(key string) interface{} {
 
 // GetWithDefault returns the value of a specific environment variable or a default value if not set.
 func GetWithDefault(key string, defaultValue interface{}) interface{} {
-	configs := LoadConfig()
-	if value, exists := configs[key]; exists {
+	LoadConfig()
+	if value, exists := lookupEnv(key); exists {
 		return value
 	}
 	return defaultValue
 }
 
+// EnvFiles returns the .env files the environment was loaded from.
+func EnvFiles() []string {
+	LoadConfig()
+	envMu.RLock()
+	defer envMu.RUnlock()
+	return append([]string(nil), envFiles...)
+}
+
 // All returns a map with all loaded environment variables.
 func All() map[string]interface{} {
-	return LoadConfig()
+	LoadConfig()
+	return environ()
 }
@@ -0,0 +1,326 @@
+package configs
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Configuration is the typed view of every configuration section.
+type Configuration struct {
+	App      AppConfig      `config:"app"`
+	Cache    CacheConfig    `config:"cache"`
+	Database DatabaseConfig `config:"database"`
+	Hashing  HashingConfig  `config:"hashing"`
+	Logging  LoggingConfig  `config:"logging"`
+}
+
+// ValidationError collects every problem found while loading the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error lists all the problems, one per line.
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// definitions returns the raw configuration tree, one section per configuration file.
+func definitions() map[string]interface{} {
+	return definitionsFrom(processEnv{})
+}
+
+// definitionsFrom builds every configuration section from the variables env reads.
+func definitionsFrom(env envReader) map[string]interface{} {
+	return map[string]interface{}{
+		"app":      appDefinition(env),
+		"cache":    cacheDefinition(env),
+		"database": databaseDefinition(env),
+		"hashing":  hashingDefinition(env),
+		"logging":  loggingDefinition(env),
+	}
+}
+
+// Load resolves the configuration, validates it and makes it current. When a
+// configuration cache exists it is loaded as is; otherwise the configuration
+// is resolved from the environment and the files in the config directory.
+func Load() (*Configuration, error) {
+	var tree map[string]interface{}
+	var err error
+	if IsCached() {
+		tree, err = readCache()
+	} else {
+		tree, err = sources()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if tree, _, err = resolveSecrets(tree); err != nil {
+		return nil, err
+	}
+
+	writeMu.Lock()
+	snap, err := resolve(tree)
+	if err != nil {
+		writeMu.Unlock()
+		return nil, err
+	}
+	previous := state.Swap(snap)
+	writeMu.Unlock()
+
+	if previous == nil {
+		// Subscribers only hear of changes, so the first level is set here
+		setLogLevel(snap.config.Logging.Level)
+	}
+	notify(previous, snap)
+	return snap.config, nil
+}
+
+// Unvalidated returns the value at a dot-notation key as a string, read from
+// the configuration cache or the environment and config files without
+// resolving secrets or validating the configuration. It is "" when the key is
+// not set. Commands that fix an invalid configuration, such as key:generate,
+// read it this way.
+func Unvalidated(key string) (string, error) {
+	var tree map[string]interface{}
+	var err error
+	if IsCached() {
+		tree, err = readCache()
+	} else {
+		tree, err = sources()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	index := make(map[string]interface{})
+	flatten("", tree, index)
+	value, ok := index[key]
+	if !ok || value == nil {
+		return "", nil
+	}
+	return toString(value), nil
+}
+
+// sources merges the config directory files over the definitions resolved from the environment.
+func sources() (map[string]interface{}, error) {
+	return sourcesFrom(processEnv{}, lookupEnv)
+}
+
+// sourcesFrom is sources with the definitions read through env and the
+// config file references expanded through lookup.
+func sourcesFrom(env envReader, lookup func(string) (string, bool)) (map[string]interface{}, error) {
+	LoadConfig()
+
+	files, _, err := readConfigFiles(lookup)
+	if err != nil {
+		return nil, err
+	}
+	return merge(definitionsFrom(env), files).(map[string]interface{}), nil
+}
+
+// parse decodes a raw configuration tree and validates the result.
+func parse(tree map[string]interface{}) (*Configuration, error) {
+	cfg := &Configuration{}
+
+	var problems []string
+	for _, err := range decode(tree, reflect.ValueOf(cfg).Elem(), "") {
+		problems = append(problems, err.Error())
+	}
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Current returns the loaded configuration, loading it on first use.
+func Current() *Configuration {
+	return current().config
+}
+
+// App returns the typed application configuration.
+func App() AppConfig {
+	return Current().App
+}
+
+// Cache returns the typed cache configuration.
+func Cache() CacheConfig {
+	return Current().Cache
+}
+
+// Database returns the typed database configuration.
+func Database() DatabaseConfig {
+	return Current().Database
+}
+
+// Hashing returns the typed password hashing configuration.
+func Hashing() HashingConfig {
+	return Current().Hashing
+}
+
+// Logging returns the typed logging configuration.
+func Logging() LoggingConfig {
+	return Current().Logging
+}
+
+// Validate reports every problem in the configuration at once.
+func (c *Configuration) Validate() error {
+	if problems := c.validate(); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (c *Configuration) validate() []string {
+	var problems []string
+
+	if c.App.Name == "" {
+		problems = append(problems, "app.name: must not be empty")
+	}
+
+	keyLength := map[string]int{"AES-128-CBC": 16, "AES-256-CBC": 32, "AES-128-GCM": 16, "AES-256-GCM": 32}
+	length, supported := keyLength[strings.ToUpper(c.App.Cipher)]
+	if !supported {
+		problems = append(problems, fmt.Sprintf("app.cipher: unsupported cipher %q", c.App.Cipher))
+	}
+	keys := append([]string{c.App.Key}, c.App.PreviousKeys...)
+	for i, key := range keys {
+		if key == "" || !supported {
+			continue
+		}
+		path := "app.key"
+		if i > 0 {
+			path = fmt.Sprintf("app.previous_keys[%d]", i-1)
+		}
+		if err := checkKey(key, length); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+
+	if _, ok := c.Cache.Stores[c.Cache.Default]; !ok {
+		problems = append(problems, fmt.Sprintf("cache.default: store %q is not defined", c.Cache.Default))
+	}
+	cacheDrivers := map[string]bool{"array": true, "database": true, "file": true, "memcached": true, "redis": true, "dynamodb": true, "swing": true}
+	for name, store := range c.Cache.Stores {
+		if !cacheDrivers[store.Driver] {
+			problems = append(problems, fmt.Sprintf("cache.stores.%s.driver: unsupported driver %q", name, store.Driver))
+		}
+		for i, server := range store.Servers {
+			if !validPort(server.Port) {
+				problems = append(problems, fmt.Sprintf("cache.stores.%s.servers[%d].port: %d is not a valid port", name, i, server.Port))
+			}
+		}
+	}
+
+	if _, ok := c.Database.Connections[c.Database.Default]; !ok {
+		problems = append(problems, fmt.Sprintf("database.default: connection %q is not defined", c.Database.Default))
+	}
+	databaseDrivers := map[string]bool{"sqlite": true, "mysql": true, "mariadb": true, "pgsql": true, "sqlsrv": true}
+	for name, conn := range c.Database.Connections {
+		if !databaseDrivers[conn.Driver] {
+			problems = append(problems, fmt.Sprintf("database.connections.%s.driver: unsupported driver %q", name, conn.Driver))
+		}
+		if !validPort(conn.Port) {
+			problems = append(problems, fmt.Sprintf("database.connections.%s.port: %d is not a valid port", name, conn.Port))
+		}
+		for side, hosts := range map[string]HostsConfig{"read": conn.Read, "write": conn.Write} {
+			if hosts.Port != 0 && !validPort(hosts.Port) {
+				problems = append(problems, fmt.Sprintf("database.connections.%s.%s.port: %d is not a valid port", name, side, hosts.Port))
+			}
+		}
+		if conn.MaxOpen < 0 {
+			problems = append(problems, fmt.Sprintf("database.connections.%s.max_open: must not be negative, got %d", name, conn.MaxOpen))
+		}
+		for key, d := range map[string]time.Duration{
+			"conn_max_lifetime":  conn.ConnMaxLifetime,
+			"conn_max_idle_time": conn.ConnMaxIdleTime,
+			"connect_timeout":    conn.ConnectTimeout,
+			"connect_backoff":    conn.ConnectBackoff,
+		} {
+			if d < 0 {
+				problems = append(problems, fmt.Sprintf("database.connections.%s.%s: must not be negative, got %s", name, key, d))
+			}
+		}
+		if conn.Driver == "sqlite" {
+			problems = append(problems, validateSQLite(name, conn)...)
+		}
+	}
+	if tx := c.Database.Transactions; tx.Attempts < 0 || tx.Backoff < 0 {
+		problems = append(problems, fmt.Sprintf("database.transactions: attempts and backoff must not be negative, got %d and %s", tx.Attempts, tx.Backoff))
+	}
+	if q := c.Database.Queries; q.SlowThreshold < 0 || q.RepeatThreshold < 0 {
+		problems = append(problems, fmt.Sprintf("database.queries: slow_threshold and repeat_threshold must not be negative, got %s and %d", q.SlowThreshold, q.RepeatThreshold))
+	}
+	for name, conn := range map[string]RedisConnection{"default": c.Database.Redis.Default, "cache": c.Database.Redis.Cache} {
+		if !validPort(conn.Port) {
+			problems = append(problems, fmt.Sprintf("database.redis.%s.port: %d is not a valid port", name, conn.Port))
+		}
+	}
+
+	switch c.Hashing.Driver {
+	case "bcrypt", "argon2id":
+	default:
+		problems = append(problems, fmt.Sprintf("hashing.driver: unsupported driver %q", c.Hashing.Driver))
+	}
+	if rounds := c.Hashing.Bcrypt.Rounds; rounds < 4 || rounds > 31 {
+		problems = append(problems, fmt.Sprintf("hashing.bcrypt.rounds: %d is not between 4 and 31", rounds))
+	}
+	argon := c.Hashing.Argon
+	if argon.Memory < 8*argon.Threads || argon.Time < 1 || argon.Threads < 1 || argon.Threads > 255 {
+		problems = append(problems, "hashing.argon: memory must be at least 8 KiB per thread, time at least 1 and threads between 1 and 255")
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "", "debug", "info", "warn", "error", "dpanic", "panic", "fatal":
+	default:
+		problems = append(problems, fmt.Sprintf("logging.level: unsupported level %q", c.Logging.Level))
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// validateSQLite checks the pragmas of a SQLite connection.
+func validateSQLite(name string, conn ConnectionConfig) []string {
+	var problems []string
+	if conn.BusyTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("database.connections.%s.busy_timeout: must not be negative, got %d", name, conn.BusyTimeout))
+	}
+	journalModes := map[string]bool{"": true, "DELETE": true, "TRUNCATE": true, "PERSIST": true, "MEMORY": true, "WAL": true, "OFF": true}
+	if !journalModes[strings.ToUpper(conn.JournalMode)] {
+		problems = append(problems, fmt.Sprintf("database.connections.%s.journal_mode: unsupported mode %q", name, conn.JournalMode))
+	}
+	synchronous := map[string]bool{"": true, "OFF": true, "NORMAL": true, "FULL": true, "EXTRA": true, "0": true, "1": true, "2": true, "3": true}
+	if !synchronous[strings.ToUpper(conn.Synchronous)] {
+		problems = append(problems, fmt.Sprintf("database.connections.%s.synchronous: unsupported value %q", name, conn.Synchronous))
+	}
+	return problems
+}
+
+// checkKey verifies that an encryption key has the length the cipher needs.
+func checkKey(key string, length int) error {
+	raw := []byte(key)
+	if encoded, ok := strings.CutPrefix(key, "base64:"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid base64 key")
+		}
+		raw = decoded
+	}
+	if len(raw) != length {
+		return fmt.Errorf("key must be %d bytes, got %d", length, len(raw))
+	}
+	return nil
+}
+
+// validPort reports whether port is unset or a valid TCP port.
+func validPort(port int) bool {
+	return port >= 0 && port <= 65535
+}
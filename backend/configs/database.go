@@ -2,112 +2,287 @@
 
 package configs
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 // GetDatabaseConfig returns database configurations as a map, similar to Laravel's database configuration file.
 func GetDatabaseConfig() map[string]interface{} {
+	return databaseDefinition(processEnv{})
+}
+
+// databaseDefinition builds the database configuration from the variables env reads.
+func databaseDefinition(env envReader) map[string]interface{} {
 	return map[string]interface{}{
-		"default": GetWithDefault("DB_CONNECTION", "sqlite"),
+		"default": env.GetWithDefault("DB_CONNECTION", "sqlite"),
 		"connections": map[string]interface{}{
 			"sqlite": map[string]interface{}{
 				"driver":                  "sqlite",
-				"url":                     Get("DB_URL"),
-				"database":                GetWithDefault("DB_DATABASE", "../../database/database.sqlite"),
+				"url":                     env.Get("DB_URL"),
+				"database":                env.GetWithDefault("DB_DATABASE", "backend/database/database.sqlite"),
 				"prefix":                  "",
-				"foreign_key_constraints": GetWithDefault("DB_FOREIGN_KEYS", true),
-				"busy_timeout":            Get("DB_BUSY_TIMEOUT"),
-				"journal_mode":            Get("DB_JOURNAL_MODE"),
-				"synchronous":             Get("DB_SYNCHRONOUS"),
+				"foreign_key_constraints": env.GetWithDefault("DB_FOREIGN_KEYS", true),
+				"busy_timeout":            env.Get("DB_BUSY_TIMEOUT"),
+				"journal_mode":            env.Get("DB_JOURNAL_MODE"),
+				"synchronous":             env.Get("DB_SYNCHRONOUS"),
+				"max_open":                env.GetWithDefault("DB_MAX_OPEN", 0),
+				"max_idle":                env.GetWithDefault("DB_MAX_IDLE", 2),
+				"conn_max_lifetime":       env.GetWithDefault("DB_CONN_MAX_LIFETIME", "0s"),
+				"conn_max_idle_time":      env.GetWithDefault("DB_CONN_MAX_IDLE_TIME", "0s"),
+				"connect_timeout":         env.GetWithDefault("DB_CONNECT_TIMEOUT", "30s"),
+				"connect_backoff":         env.GetWithDefault("DB_CONNECT_BACKOFF", "250ms"),
 			},
 			"mysql": map[string]interface{}{
-				"driver":         "mysql",
-				"url":            Get("DB_URL"),
-				"host":           GetWithDefault("DB_HOST", "127.0.0.1"),
-				"port":           GetWithDefault("DB_PORT", "3306"),
-				"database":       GetWithDefault("DB_DATABASE", "jazz"),
-				"username":       GetWithDefault("DB_USERNAME", "root"),
-				"password":       Get("DB_PASSWORD"),
-				"unix_socket":    Get("DB_SOCKET"),
-				"charset":        GetWithDefault("DB_CHARSET", "utf8mb4"),
-				"collation":      GetWithDefault("DB_COLLATION", "utf8mb4_unicode_ci"),
-				"prefix":         "",
-				"prefix_indexes": true,
-				"strict":         true,
-				"engine":         Get("DB_ENGINE"),
-				"options":        map[string]interface{}{"ssl_ca": Get("MYSQL_ATTR_SSL_CA")},
+				"driver":             "mysql",
+				"url":                env.Get("DB_URL"),
+				"host":               env.GetWithDefault("DB_HOST", "127.0.0.1"),
+				"port":               env.GetWithDefault("DB_PORT", "3306"),
+				"database":           env.GetWithDefault("DB_DATABASE", "jazz"),
+				"username":           env.GetWithDefault("DB_USERNAME", "root"),
+				"password":           env.Get("DB_PASSWORD"),
+				"unix_socket":        env.Get("DB_SOCKET"),
+				"charset":            env.GetWithDefault("DB_CHARSET", "utf8mb4"),
+				"collation":          env.GetWithDefault("DB_COLLATION", "utf8mb4_unicode_ci"),
+				"prefix":             "",
+				"prefix_indexes":     true,
+				"strict":             true,
+				"engine":             env.Get("DB_ENGINE"),
+				"options":            map[string]interface{}{"ssl_ca": env.Get("MYSQL_ATTR_SSL_CA")},
+				"max_open":           env.GetWithDefault("DB_MAX_OPEN", 0),
+				"max_idle":           env.GetWithDefault("DB_MAX_IDLE", 2),
+				"conn_max_lifetime":  env.GetWithDefault("DB_CONN_MAX_LIFETIME", "0s"),
+				"conn_max_idle_time": env.GetWithDefault("DB_CONN_MAX_IDLE_TIME", "0s"),
+				"connect_timeout":    env.GetWithDefault("DB_CONNECT_TIMEOUT", "30s"),
+				"connect_backoff":    env.GetWithDefault("DB_CONNECT_BACKOFF", "250ms"),
+				"read":               map[string]interface{}{"host": env.Get("DB_READ_HOST")},
+				"write":              map[string]interface{}{"host": env.Get("DB_WRITE_HOST")},
+				"sticky":             env.GetWithDefault("DB_STICKY", false),
 			},
 			"mariadb": map[string]interface{}{
-				"driver":         "mariadb",
-				"url":            Get("DB_URL"),
-				"host":           GetWithDefault("DB_HOST", "127.0.0.1"),
-				"port":           GetWithDefault("DB_PORT", "3306"),
-				"database":       GetWithDefault("DB_DATABASE", "jazz"),
-				"username":       GetWithDefault("DB_USERNAME", "root"),
-				"password":       Get("DB_PASSWORD"),
-				"unix_socket":    Get("DB_SOCKET"),
-				"charset":        GetWithDefault("DB_CHARSET", "utf8mb4"),
-				"collation":      GetWithDefault("DB_COLLATION", "utf8mb4_unicode_ci"),
-				"prefix":         "",
-				"prefix_indexes": true,
-				"strict":         true,
-				"engine":         Get("DB_ENGINE"),
-				"options":        map[string]interface{}{"ssl_ca": Get("MYSQL_ATTR_SSL_CA")},
+				"driver":             "mariadb",
+				"url":                env.Get("DB_URL"),
+				"host":               env.GetWithDefault("DB_HOST", "127.0.0.1"),
+				"port":               env.GetWithDefault("DB_PORT", "3306"),
+				"database":           env.GetWithDefault("DB_DATABASE", "jazz"),
+				"username":           env.GetWithDefault("DB_USERNAME", "root"),
+				"password":           env.Get("DB_PASSWORD"),
+				"unix_socket":        env.Get("DB_SOCKET"),
+				"charset":            env.GetWithDefault("DB_CHARSET", "utf8mb4"),
+				"collation":          env.GetWithDefault("DB_COLLATION", "utf8mb4_unicode_ci"),
+				"prefix":             "",
+				"prefix_indexes":     true,
+				"strict":             true,
+				"engine":             env.Get("DB_ENGINE"),
+				"options":            map[string]interface{}{"ssl_ca": env.Get("MYSQL_ATTR_SSL_CA")},
+				"max_open":           env.GetWithDefault("DB_MAX_OPEN", 0),
+				"max_idle":           env.GetWithDefault("DB_MAX_IDLE", 2),
+				"conn_max_lifetime":  env.GetWithDefault("DB_CONN_MAX_LIFETIME", "0s"),
+				"conn_max_idle_time": env.GetWithDefault("DB_CONN_MAX_IDLE_TIME", "0s"),
+				"connect_timeout":    env.GetWithDefault("DB_CONNECT_TIMEOUT", "30s"),
+				"connect_backoff":    env.GetWithDefault("DB_CONNECT_BACKOFF", "250ms"),
+				"read":               map[string]interface{}{"host": env.Get("DB_READ_HOST")},
+				"write":              map[string]interface{}{"host": env.Get("DB_WRITE_HOST")},
+				"sticky":             env.GetWithDefault("DB_STICKY", false),
 			},
 			"pgsql": map[string]interface{}{
-				"driver":         "pgsql",
-				"url":            Get("DB_URL"),
-				"host":           GetWithDefault("DB_HOST", "127.0.0.1"),
-				"port":           GetWithDefault("DB_PORT", "5432"),
-				"database":       GetWithDefault("DB_DATABASE", "jazz"),
-				"username":       GetWithDefault("DB_USERNAME", "root"),
-				"password":       Get("DB_PASSWORD"),
-				"charset":        GetWithDefault("DB_CHARSET", "utf8"),
-				"prefix":         "",
-				"prefix_indexes": true,
-				"search_path":    GetWithDefault("DB_SEARCH_PATH", "public"),
-				"sslmode":        GetWithDefault("DB_SSLMODE", "prefer"),
+				"driver":             "pgsql",
+				"url":                env.Get("DB_URL"),
+				"host":               env.GetWithDefault("DB_HOST", "127.0.0.1"),
+				"port":               env.GetWithDefault("DB_PORT", "5432"),
+				"database":           env.GetWithDefault("DB_DATABASE", "jazz"),
+				"username":           env.GetWithDefault("DB_USERNAME", "root"),
+				"password":           env.Get("DB_PASSWORD"),
+				"charset":            env.GetWithDefault("DB_CHARSET", "utf8"),
+				"prefix":             "",
+				"prefix_indexes":     true,
+				"search_path":        env.GetWithDefault("DB_SEARCH_PATH", "public"),
+				"sslmode":            env.GetWithDefault("DB_SSLMODE", "prefer"),
+				"options":            map[string]interface{}{"ssl_ca": env.Get("DB_SSL_CA")},
+				"max_open":           env.GetWithDefault("DB_MAX_OPEN", 0),
+				"max_idle":           env.GetWithDefault("DB_MAX_IDLE", 2),
+				"conn_max_lifetime":  env.GetWithDefault("DB_CONN_MAX_LIFETIME", "0s"),
+				"conn_max_idle_time": env.GetWithDefault("DB_CONN_MAX_IDLE_TIME", "0s"),
+				"connect_timeout":    env.GetWithDefault("DB_CONNECT_TIMEOUT", "30s"),
+				"connect_backoff":    env.GetWithDefault("DB_CONNECT_BACKOFF", "250ms"),
+				"read":               map[string]interface{}{"host": env.Get("DB_READ_HOST")},
+				"write":              map[string]interface{}{"host": env.Get("DB_WRITE_HOST")},
+				"sticky":             env.GetWithDefault("DB_STICKY", false),
 			},
 			"sqlsrv": map[string]interface{}{
 				"driver":                   "sqlsrv",
-				"url":                      Get("DB_URL"),
-				"host":                     GetWithDefault("DB_HOST", "localhost"),
-				"port":                     GetWithDefault("DB_PORT", "1433"),
-				"database":                 GetWithDefault("DB_DATABASE", "jazz"),
-				"username":                 GetWithDefault("DB_USERNAME", "root"),
-				"password":                 Get("DB_PASSWORD"),
-				"charset":                  GetWithDefault("DB_CHARSET", "utf8"),
+				"url":                      env.Get("DB_URL"),
+				"host":                     env.GetWithDefault("DB_HOST", "localhost"),
+				"port":                     env.GetWithDefault("DB_PORT", "1433"),
+				"database":                 env.GetWithDefault("DB_DATABASE", "jazz"),
+				"username":                 env.GetWithDefault("DB_USERNAME", "root"),
+				"password":                 env.Get("DB_PASSWORD"),
+				"charset":                  env.GetWithDefault("DB_CHARSET", "utf8"),
 				"prefix":                   "",
 				"prefix_indexes":           true,
-				"encrypt":                  Get("DB_ENCRYPT"),
-				"trust_server_certificate": Get("DB_TRUST_SERVER_CERTIFICATE"),
+				"encrypt":                  env.Get("DB_ENCRYPT"),
+				"trust_server_certificate": env.Get("DB_TRUST_SERVER_CERTIFICATE"),
+				"options":                  map[string]interface{}{"ssl_ca": env.Get("DB_SSL_CA")},
+				"max_open":                 env.GetWithDefault("DB_MAX_OPEN", 0),
+				"max_idle":                 env.GetWithDefault("DB_MAX_IDLE", 2),
+				"conn_max_lifetime":        env.GetWithDefault("DB_CONN_MAX_LIFETIME", "0s"),
+				"conn_max_idle_time":       env.GetWithDefault("DB_CONN_MAX_IDLE_TIME", "0s"),
+				"connect_timeout":          env.GetWithDefault("DB_CONNECT_TIMEOUT", "30s"),
+				"connect_backoff":          env.GetWithDefault("DB_CONNECT_BACKOFF", "250ms"),
+				"read":                     map[string]interface{}{"host": env.Get("DB_READ_HOST")},
+				"write":                    map[string]interface{}{"host": env.Get("DB_WRITE_HOST")},
+				"sticky":                   env.GetWithDefault("DB_STICKY", false),
 			},
 		},
 		"migrations": map[string]interface{}{
-			"table":                  GetWithDefault("DB_MIGRATIONS_TABLE", "migrations"),
-			"update_date_on_publish": GetWithDefault("DB_UPDATE_DATE_ON_PUBLISH", true),
+			"table":                  env.GetWithDefault("DB_MIGRATIONS_TABLE", "migrations"),
+			"update_date_on_publish": env.GetWithDefault("DB_UPDATE_DATE_ON_PUBLISH", true),
+		},
+		"transactions": map[string]interface{}{
+			"attempts": env.GetWithDefault("DB_TRANSACTION_ATTEMPTS", 3),
+			"backoff":  env.GetWithDefault("DB_TRANSACTION_BACKOFF", "50ms"),
+		},
+		"queries": map[string]interface{}{
+			"slow_threshold":   env.GetWithDefault("DB_SLOW_QUERY_THRESHOLD", "200ms"),
+			"slow_channel":     env.GetWithDefault("DB_SLOW_QUERY_CHANNEL", "slow_queries"),
+			"slow_bindings":    env.GetWithDefault("DB_SLOW_QUERY_BINDINGS", false),
+			"repeat_threshold": env.GetWithDefault("DB_QUERY_REPEAT_THRESHOLD", 10),
 		},
 		"redis": map[string]interface{}{
-			"client": GetWithDefault("REDIS_CLIENT", "redis"),
+			"client": env.GetWithDefault("REDIS_CLIENT", "redis"),
 			"options": map[string]interface{}{
-				"cluster": GetWithDefault("REDIS_CLUSTER", "redis"),
-				"prefix":  strings.ToLower(strings.ReplaceAll(GetWithDefault("CACHE_PREFIX", GetWithDefault("APP_NAME", "jazz").(string)+"_cache_").(string), " ", "_")),
+				"cluster": env.GetWithDefault("REDIS_CLUSTER", "redis"),
+				"prefix":  strings.ToLower(strings.ReplaceAll(toString(env.GetWithDefault("CACHE_PREFIX", toString(env.GetWithDefault("APP_NAME", "jazz"))+"_cache_")), " ", "_")),
 			},
 			"default": map[string]interface{}{
-				"url":      Get("REDIS_URL"),
-				"host":     GetWithDefault("REDIS_HOST", "127.0.0.1"),
-				"username": Get("REDIS_USERNAME"),
-				"password": Get("REDIS_PASSWORD"),
-				"port":     GetWithDefault("REDIS_PORT", "6379"),
-				"database": GetWithDefault("REDIS_DB", "0"),
+				"url":      env.Get("REDIS_URL"),
+				"host":     env.GetWithDefault("REDIS_HOST", "127.0.0.1"),
+				"username": env.Get("REDIS_USERNAME"),
+				"password": env.Get("REDIS_PASSWORD"),
+				"port":     env.GetWithDefault("REDIS_PORT", "6379"),
+				"database": env.GetWithDefault("REDIS_DB", "0"),
 			},
 			"cache": map[string]interface{}{
-				"url":      Get("REDIS_URL"),
-				"host":     GetWithDefault("REDIS_HOST", "127.0.0.1"),
-				"username": Get("REDIS_USERNAME"),
-				"password": Get("REDIS_PASSWORD"),
-				"port":     GetWithDefault("REDIS_PORT", "6379"),
-				"database": GetWithDefault("REDIS_CACHE_DB", "1"),
+				"url":      env.Get("REDIS_URL"),
+				"host":     env.GetWithDefault("REDIS_HOST", "127.0.0.1"),
+				"username": env.Get("REDIS_USERNAME"),
+				"password": env.Get("REDIS_PASSWORD"),
+				"port":     env.GetWithDefault("REDIS_PORT", "6379"),
+				"database": env.GetWithDefault("REDIS_CACHE_DB", "1"),
 			},
 		},
 	}
 }
+
+// DatabaseConfig holds the typed database settings.
+type DatabaseConfig struct {
+	Default      string                      `config:"default"`
+	Connections  map[string]ConnectionConfig `config:"connections"`
+	Migrations   MigrationsConfig            `config:"migrations"`
+	Transactions TransactionsConfig          `config:"transactions"`
+	Queries      QueriesConfig               `config:"queries"`
+	Redis        RedisConfig                 `config:"redis"`
+}
+
+// ConnectionConfig holds the settings of a database connection. Each driver only uses the fields it needs.
+type ConnectionConfig struct {
+	Driver                 string            `config:"driver"`
+	URL                    string            `config:"url"`
+	Host                   string            `config:"host"`
+	Port                   int               `config:"port"`
+	Database               string            `config:"database"`
+	Username               string            `config:"username"`
+	Password               string            `config:"password"`
+	UnixSocket             string            `config:"unix_socket"`
+	Charset                string            `config:"charset"`
+	Collation              string            `config:"collation"`
+	Prefix                 string            `config:"prefix"`
+	PrefixIndexes          bool              `config:"prefix_indexes"`
+	Strict                 bool              `config:"strict"`
+	Engine                 string            `config:"engine"`
+	Options                map[string]string `config:"options"`
+	SearchPath             string            `config:"search_path"`
+	SSLMode                string            `config:"sslmode"`
+	Encrypt                string            `config:"encrypt"`
+	TrustServerCertificate bool              `config:"trust_server_certificate"`
+	ForeignKeyConstraints  bool              `config:"foreign_key_constraints"`
+	// BusyTimeout is in milliseconds.
+	BusyTimeout int    `config:"busy_timeout"`
+	JournalMode string `config:"journal_mode"`
+	Synchronous string `config:"synchronous"`
+	// MaxOpen, MaxIdle, ConnMaxLifetime and ConnMaxIdleTime tune the pool.
+	// A negative MaxIdle keeps no idle connections.
+	MaxOpen         int           `config:"max_open"`
+	MaxIdle         int           `config:"max_idle"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time"`
+	// ConnectTimeout bounds the connection retries, which wait ConnectBackoff
+	// after the first failure and twice as long after each following one.
+	ConnectTimeout time.Duration `config:"connect_timeout"`
+	ConnectBackoff time.Duration `config:"connect_backoff"`
+	// Read and Write split queries between replicas and primaries. Sticky
+	// sends the reads of a request that wrote data to the primary.
+	Read   HostsConfig `config:"read"`
+	Write  HostsConfig `config:"write"`
+	Sticky bool        `config:"sticky"`
+}
+
+// HostsConfig lists the hosts of one side of a read/write split. Empty
+// fields fall back to the connection's own settings.
+type HostsConfig struct {
+	Host     []string `config:"host"`
+	Port     int      `config:"port"`
+	Username string   `config:"username"`
+	Password string   `config:"password"`
+}
+
+// MigrationsConfig holds the migration repository settings.
+type MigrationsConfig struct {
+	Table               string `config:"table"`
+	UpdateDateOnPublish bool   `config:"update_date_on_publish"`
+}
+
+// TransactionsConfig holds the retry policy of database.Transaction. A
+// transaction failing on a deadlock or serialization failure is run up to
+// Attempts times in all, or once when Attempts is 0, waiting Backoff longer
+// before each new attempt.
+type TransactionsConfig struct {
+	Attempts int           `config:"attempts"`
+	Backoff  time.Duration `config:"backoff"`
+}
+
+// QueriesConfig holds the query monitoring settings. Queries slower than
+// SlowThreshold are logged to the SlowChannel log, with their bound values
+// only when SlowBindings is set, and a request running the same query more
+// than RepeatThreshold times is reported as a likely N+1 problem. Zero
+// disables either check.
+type QueriesConfig struct {
+	SlowThreshold   time.Duration `config:"slow_threshold"`
+	SlowChannel     string        `config:"slow_channel"`
+	SlowBindings    bool          `config:"slow_bindings"`
+	RepeatThreshold int           `config:"repeat_threshold"`
+}
+
+// RedisConfig holds the Redis client settings and its named connections.
+type RedisConfig struct {
+	Client  string          `config:"client"`
+	Options RedisOptions    `config:"options"`
+	Default RedisConnection `config:"default"`
+	Cache   RedisConnection `config:"cache"`
+}
+
+// RedisOptions holds the options shared by every Redis connection.
+type RedisOptions struct {
+	Cluster string `config:"cluster"`
+	Prefix  string `config:"prefix"`
+}
+
+// RedisConnection holds the settings of a single Redis connection.
+type RedisConnection struct {
+	URL      string `config:"url"`
+	Host     string `config:"host"`
+	Username string `config:"username"`
+	Password string `config:"password"`
+	Port     int    `config:"port"`
+	Database int    `config:"database"`
+}
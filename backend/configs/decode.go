@@ -0,0 +1,308 @@
+package configs
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// decode copies a raw configuration value (strings from the environment, or
+// maps, slices and scalars from other sources) into dst, converting types as
+// it goes. Every conversion problem is reported with its dot-notation path.
+func decode(src interface{}, dst reflect.Value, path string) []error {
+	if src == nil {
+		return nil
+	}
+
+	if dst.Kind() == reflect.Interface {
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	}
+
+	if dst.Type() == durationType {
+		d, err := toDuration(src)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %v", path, err)}
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		values, ok := toMap(src)
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a map, got %T", path, src)}
+		}
+		var errs []error
+		for i := 0; i < dst.NumField(); i++ {
+			name := dst.Type().Field(i).Tag.Get("config")
+			if name == "" || name == "-" {
+				continue
+			}
+			errs = append(errs, decode(values[name], dst.Field(i), join(path, name))...)
+		}
+		return errs
+
+	case reflect.Map:
+		values, ok := toMap(src)
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a map, got %T", path, src)}
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(values)))
+		}
+		var errs []error
+		for key, value := range values {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if existing := dst.MapIndex(reflect.ValueOf(key)); existing.IsValid() {
+				elem.Set(existing)
+			}
+			errs = append(errs, decode(value, elem, join(path, key))...)
+			dst.SetMapIndex(reflect.ValueOf(key), elem)
+		}
+		return errs
+
+	case reflect.Slice:
+		items, ok := toSlice(src)
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a list, got %T", path, src)}
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		var errs []error
+		for i, item := range items {
+			errs = append(errs, decode(item, slice.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		dst.Set(slice)
+		return errs
+
+	case reflect.String:
+		dst.SetString(toString(src))
+
+	case reflect.Bool:
+		b, err := toBool(src)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %v", path, err)}
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt(src)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %v", path, err)}
+		}
+		if dst.OverflowInt(n) {
+			return []error{fmt.Errorf("%s: %d is out of range", path, n)}
+		}
+		dst.SetInt(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat(src)
+		if err != nil {
+			return []error{fmt.Errorf("%s: %v", path, err)}
+		}
+		dst.SetFloat(f)
+
+	default:
+		return []error{fmt.Errorf("%s: unsupported configuration type %s", path, dst.Type())}
+	}
+
+	return nil
+}
+
+// encode turns a typed configuration value back into a tree of maps, slices
+// and scalars keyed by the `config` tags.
+func encode(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int())
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		tree := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("config")
+			if name == "" || name == "-" {
+				continue
+			}
+			tree[name] = encode(v.Field(i))
+		}
+		return tree
+	case reflect.Map:
+		tree := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			tree[fmt.Sprint(iter.Key().Interface())] = encode(iter.Value())
+		}
+		return tree
+	case reflect.Slice:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = encode(v.Index(i))
+		}
+		return items
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return v.Elem().Interface()
+	default:
+		return v.Interface()
+	}
+}
+
+// join appends key to a dot-notation path.
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// toMap normalizes the map types produced by the configuration sources.
+func toMap(src interface{}) (map[string]interface{}, bool) {
+	switch m := src.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[string]string:
+		values := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			values[k] = v
+		}
+		return values, true
+	case map[interface{}]interface{}:
+		values := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			values[fmt.Sprint(k)] = v
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// toSlice normalizes lists, splitting strings on commas as environment lists are written.
+func toSlice(src interface{}) ([]interface{}, bool) {
+	if s, ok := src.(string); ok {
+		var items []interface{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, true
+	}
+
+	v := reflect.ValueOf(src)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, true
+}
+
+// toString renders a scalar as a string. Like Laravel's env(), a literal
+// "null" or "(null)" is treated as an empty value.
+func toString(src interface{}) string {
+	s := fmt.Sprint(src)
+	switch strings.ToLower(s) {
+	case "null", "(null)":
+		return ""
+	}
+	return s
+}
+
+func toBool(src interface{}) (bool, error) {
+	switch v := src.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "null", "(null)", "false", "(false)", "0", "no", "off":
+			return false, nil
+		case "true", "(true)", "1", "yes", "on":
+			return true, nil
+		}
+		return false, fmt.Errorf("invalid boolean %q", v)
+	}
+	if n, err := toFloat(src); err == nil {
+		return n != 0, nil
+	}
+	return false, fmt.Errorf("invalid boolean %v", src)
+}
+
+func toInt(src interface{}) (int64, error) {
+	if s, ok := src.(string); ok {
+		s = strings.TrimSpace(s)
+		if s == "" || strings.EqualFold(s, "null") {
+			return 0, nil
+		}
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid integer %q", s)
+		}
+		return n, nil
+	}
+
+	f, err := toFloat(src)
+	if err != nil || f != math.Trunc(f) {
+		return 0, fmt.Errorf("invalid integer %v", src)
+	}
+	return int64(f), nil
+}
+
+func toFloat(src interface{}) (float64, error) {
+	v := reflect.ValueOf(src)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		s := strings.TrimSpace(v.String())
+		if s == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("invalid number %v", src)
+}
+
+// toDuration accepts Go duration strings ("1h30m") and treats bare numbers as seconds.
+func toDuration(src interface{}) (time.Duration, error) {
+	switch v := src.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if s == "" {
+			return 0, nil
+		}
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return time.Duration(n * float64(time.Second)), nil
+		}
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	n, err := toFloat(src)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %v", src)
+	}
+	return time.Duration(n * float64(time.Second)), nil
+}
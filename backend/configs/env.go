@@ -0,0 +1,311 @@
+package configs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	projectRoot     string
+	projectRootOnce sync.Once
+)
+
+// envEntry is a single assignment read from a .env file.
+type envEntry struct {
+	value string
+	// literal values were single-quoted and are never interpolated.
+	literal bool
+	// file is the .env file the value was read from.
+	file string
+}
+
+// ProjectRoot returns the directory holding the project's go.mod or .env,
+// searching upwards from the working directory and then from the executable.
+func ProjectRoot() string {
+	projectRootOnce.Do(func() {
+		var starts []string
+		if cwd, err := os.Getwd(); err == nil {
+			starts = append(starts, cwd)
+		}
+		if executable, err := os.Executable(); err == nil {
+			starts = append(starts, filepath.Dir(executable))
+		}
+
+		for _, start := range starts {
+			if root, ok := findRoot(start); ok {
+				projectRoot = root
+				return
+			}
+		}
+
+		// Nothing found: behave as if the working directory were the root.
+		if len(starts) > 0 {
+			projectRoot = starts[0]
+		}
+	})
+	return projectRoot
+}
+
+// findRoot walks up from dir until it finds a go.mod or .env file.
+func findRoot(dir string) (string, bool) {
+	for {
+		for _, marker := range []string{"go.mod", ".env"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// BasePath returns a path relative to the project root.
+func BasePath(elem ...string) string {
+	return filepath.Join(append([]string{ProjectRoot()}, elem...)...)
+}
+
+// resolvePath makes a relative path absolute against the project root.
+func resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return BasePath(path)
+}
+
+// EnvFilePath returns the base .env file: JAZZ_ENV_FILE when set, otherwise .env in the project root.
+func EnvFilePath() string {
+	if path := os.Getenv("JAZZ_ENV_FILE"); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return path
+	}
+	return BasePath(".env")
+}
+
+// EnvFileFor returns the .env file whose assignment of key takes effect: the
+// last of the base file and its overlays that sets it, or the base file when
+// none does. It fails when the real environment or a KEY_FILE sets key, as no
+// .env file would win over them.
+func EnvFileFor(key string) (string, error) {
+	LoadConfig()
+	if _, ok := realEnv(key); ok {
+		return "", fmt.Errorf("%s is set in the environment, which overrides the .env files", key)
+	}
+	if _, ok := secretFile(key); ok {
+		return "", fmt.Errorf("%s is read from %s_FILE, which overrides the .env files", key, key)
+	}
+
+	entries, _, err := readEnvFiles()
+	if err != nil {
+		return "", err
+	}
+	if entry, ok := entries[key]; ok {
+		return entry.file, nil
+	}
+	return EnvFilePath(), nil
+}
+
+// readEnvFiles reads the base .env file and its .env.{APP_ENV} and .env.local
+// overlays, later files overriding earlier ones. Missing files are skipped.
+func readEnvFiles() (map[string]envEntry, []string, error) {
+	base := EnvFilePath()
+	values := make(map[string]envEntry)
+	var loaded []string
+
+	layer := func(path string) error {
+		entries, err := parseEnvFile(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for key, entry := range entries {
+			entry.file = path
+			values[key] = entry
+		}
+		loaded = append(loaded, path)
+		return nil
+	}
+
+	if err := layer(base); err != nil {
+		return nil, nil, err
+	}
+
+	// The real environment decides which overlay applies, then the base file.
+	env, ok := realEnv("APP_ENV")
+	if !ok {
+		env = values["APP_ENV"].value
+	}
+	if env != "" {
+		if err := layer(base + "." + env); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := layer(base + ".local"); err != nil {
+		return nil, nil, err
+	}
+
+	return values, loaded, nil
+}
+
+// parseEnvFile reads KEY=VALUE assignments from a .env file. Values may be
+// unquoted (trailing " # comments" are stripped), single-quoted (taken
+// literally) or double-quoted (escapes are expanded and may span lines).
+func parseEnvFile(path string) (map[string]envEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make(map[string]envEntry)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%s:%d: invalid line %q", path, lineNumber, line)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.LastIndex(value, "'")
+			if end == 0 {
+				return nil, fmt.Errorf("%s:%d: unterminated quoted value", path, lineNumber)
+			}
+			entries[key] = envEntry{value: value[1:end], literal: true}
+
+		case strings.HasPrefix(value, `"`):
+			// Keep reading lines until the closing quote for multi-line values.
+			for !closesDoubleQuote(value) {
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("%s:%d: unterminated quoted value", path, lineNumber)
+				}
+				lineNumber++
+				value += "\n" + scanner.Text()
+			}
+			end := strings.LastIndex(value, `"`)
+			entries[key] = envEntry{value: unescape(value[1:end])}
+
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			entries[key] = envEntry{value: value}
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// closesDoubleQuote reports whether a value starting with a double quote has its closing quote.
+func closesDoubleQuote(value string) bool {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return true
+		}
+	}
+	return false
+}
+
+// unescape expands the escape sequences allowed in double-quoted values.
+// Escaped dollar signs are kept escaped so interpolation leaves them alone.
+func unescape(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\r`, "\r", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value)
+}
+
+var variablePattern = regexp.MustCompile(`\\?\$(\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandVariables replaces ${NAME}, ${NAME:-default} and $NAME references
+// using lookup. Unknown variables expand to their default, or to nothing.
+// A reference preceded by a backslash is kept as written.
+func expandVariables(value string, lookup func(string) (string, bool)) string {
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		if strings.HasPrefix(match, `\`) {
+			return match[1:]
+		}
+
+		groups := variablePattern.FindStringSubmatch(match)
+		name := groups[2]
+		if name == "" {
+			name = groups[5]
+		}
+		if resolved, ok := lookup(name); ok && resolved != "" {
+			return resolved
+		}
+		return groups[4]
+	})
+}
+
+// interpolate expands the references in every .env value. The real
+// environment takes precedence over other .env values, and references
+// between .env values are resolved recursively.
+func interpolate(entries map[string]envEntry) map[string]string {
+	resolved := make(map[string]string, len(entries))
+	resolving := make(map[string]bool)
+
+	var resolve func(key string) (string, bool)
+	resolve = func(key string) (string, bool) {
+		if value, ok := realEnv(key); ok {
+			return value, true
+		}
+		if value, ok := resolved[key]; ok {
+			return value, true
+		}
+		entry, ok := entries[key]
+		if !ok || resolving[key] {
+			return "", false
+		}
+		if entry.literal {
+			resolved[key] = entry.value
+			return entry.value, true
+		}
+
+		resolving[key] = true
+		value := expandVariables(entry.value, resolve)
+		resolving[key] = false
+
+		resolved[key] = value
+		return value, true
+	}
+
+	values := make(map[string]string, len(entries))
+	for key, entry := range entries {
+		if entry.literal {
+			values[key] = entry.value
+			continue
+		}
+		// Expand the file's own value even if the real environment overrides it.
+		resolving[key] = true
+		values[key] = expandVariables(entry.value, resolve)
+		resolving[key] = false
+	}
+	return values
+}
@@ -0,0 +1,114 @@
+package configs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configPath returns the directory holding the configuration files:
+// JAZZ_CONFIG_PATH when set, otherwise config/ in the project root.
+func configPath() string {
+	if path := os.Getenv("JAZZ_CONFIG_PATH"); path != "" {
+		return path
+	}
+	return BasePath("config")
+}
+
+// readConfigFiles loads every YAML, TOML and JSON file in the configuration
+// directory. Each file becomes the section named after it, so config/cache.yaml
+// is merged over the "cache" section. ${ENV} references in string values are
+// interpolated from the environment.
+func readConfigFiles(lookup func(string) (string, bool)) (map[string]interface{}, []string, error) {
+	dir := configPath()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config directory %s: %w", dir, err)
+	}
+
+	// Read files in a stable order so sections split across formats merge predictably.
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	sections := make(map[string]interface{})
+	var loaded []string
+	for _, name := range names {
+		ext := filepath.Ext(name)
+		unmarshal, supported := configFormats[strings.ToLower(ext)]
+		if !supported {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+
+		values := map[string]interface{}{}
+		if err := unmarshal(content, &values); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+
+		section := strings.TrimSuffix(name, ext)
+		if existing, ok := sections[section]; ok {
+			sections[section] = merge(existing, expandTree(values, lookup))
+		} else {
+			sections[section] = expandTree(values, lookup)
+		}
+		loaded = append(loaded, path)
+	}
+
+	return sections, loaded, nil
+}
+
+// configFormats maps the supported file extensions to their decoders.
+var configFormats = map[string]func([]byte, interface{}) error{
+	".yaml": yaml.Unmarshal,
+	".yml":  yaml.Unmarshal,
+	".toml": toml.Unmarshal,
+	".json": json.Unmarshal,
+}
+
+// expandTree interpolates the references in every string of a tree using lookup.
+func expandTree(value interface{}, lookup func(string) (string, bool)) interface{} {
+	if m, ok := toMap(value); ok {
+		expanded := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			expanded[k] = expandTree(v, lookup)
+		}
+		return expanded
+	}
+
+	switch v := value.(type) {
+	case string:
+		return expandVariables(v, lookup)
+	case []interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded[i] = expandTree(item, lookup)
+		}
+		return expanded
+	case []map[string]interface{}:
+		expanded := make([]interface{}, len(v))
+		for i, item := range v {
+			expanded[i] = expandTree(item, lookup)
+		}
+		return expanded
+	}
+	return value
+}
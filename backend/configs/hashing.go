@@ -0,0 +1,40 @@
+package configs
+
+// GetHashingConfig returns password hashing configurations as a map, similar to Laravel's hashing configuration file.
+func GetHashingConfig() map[string]interface{} {
+	return hashingDefinition(processEnv{})
+}
+
+// hashingDefinition builds the hashing configuration from the variables env reads.
+func hashingDefinition(env envReader) map[string]interface{} {
+	return map[string]interface{}{
+		"driver": env.GetWithDefault("HASH_DRIVER", "bcrypt"),
+		"bcrypt": map[string]interface{}{
+			"rounds": env.GetWithDefault("BCRYPT_ROUNDS", 12),
+		},
+		"argon": map[string]interface{}{
+			"memory":  env.GetWithDefault("ARGON_MEMORY", 65536),
+			"threads": env.GetWithDefault("ARGON_THREADS", 1),
+			"time":    env.GetWithDefault("ARGON_TIME", 4),
+		},
+	}
+}
+
+// HashingConfig holds the typed password hashing settings.
+type HashingConfig struct {
+	Driver string       `config:"driver"`
+	Bcrypt BcryptConfig `config:"bcrypt"`
+	Argon  ArgonConfig  `config:"argon"`
+}
+
+// BcryptConfig holds the bcrypt cost.
+type BcryptConfig struct {
+	Rounds int `config:"rounds"`
+}
+
+// ArgonConfig holds the argon2id parameters. Memory is in KiB.
+type ArgonConfig struct {
+	Memory  int `config:"memory"`
+	Threads int `config:"threads"`
+	Time    int `config:"time"`
+}
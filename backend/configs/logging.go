@@ -0,0 +1,38 @@
+package configs
+
+import "jazz/backend/pkg/logger"
+
+// GetLoggingConfig returns logging configurations as a map, similar to Laravel's logging configuration file.
+func GetLoggingConfig() map[string]interface{} {
+	return loggingDefinition(processEnv{})
+}
+
+// loggingDefinition builds the logging configuration from the variables env reads.
+func loggingDefinition(env envReader) map[string]interface{} {
+	return map[string]interface{}{
+		"level": env.Get("LOG_LEVEL"),
+	}
+}
+
+// LoggingConfig holds the typed logging settings.
+type LoggingConfig struct {
+	// Level is the lowest level logged, such as "debug" or "warn". Empty
+	// keeps the default of APP_ENV. Changes apply on reload.
+	Level string `config:"level"`
+}
+
+func init() {
+	// The logger cannot import configs, so it is told where the root is.
+	logger.SetRoot(ProjectRoot())
+	OnChange("logging.level", func(old, new interface{}) {
+		level, _ := new.(string)
+		setLogLevel(level)
+	})
+}
+
+// setLogLevel applies logging.level to the application logger and its channels.
+func setLogLevel(level string) {
+	if err := logger.SetLevel(level); err != nil {
+		logger.Logger.Errorw("Failed to set the log level", "level", level, "error", err)
+	}
+}
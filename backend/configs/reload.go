@@ -0,0 +1,201 @@
+package configs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"jazz/backend/pkg/logger"
+)
+
+// subscription is a callback registered with OnChange.
+type subscription struct {
+	key string
+	fn  func(old, new interface{})
+}
+
+var (
+	reloadMu      sync.Mutex
+	subscribersMu sync.RWMutex
+	subscribers   []*subscription
+)
+
+// OnChange calls fn whenever the value at a dot-notation key changes, after
+// a reload or a Set. Subscribing to a section such as "cache" fires for any
+// change below it. The returned function removes the subscription.
+func OnChange(key string, fn func(old, new interface{})) func() {
+	sub := &subscription{key: key, fn: fn}
+
+	subscribersMu.Lock()
+	subscribers = append(subscribers, sub)
+	subscribersMu.Unlock()
+
+	return func() {
+		subscribersMu.Lock()
+		defer subscribersMu.Unlock()
+		for i, s := range subscribers {
+			if s == sub {
+				subscribers = append(subscribers[:i:i], subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// notify calls the subscribers whose key differs between two snapshots.
+func notify(previous, next *snapshot) {
+	if previous == nil || previous == next {
+		return
+	}
+
+	subscribersMu.RLock()
+	subs := append([]*subscription(nil), subscribers...)
+	subscribersMu.RUnlock()
+
+	for _, sub := range subs {
+		old, _ := previous.get(sub.key)
+		value, _ := next.get(sub.key)
+		if reflect.DeepEqual(old, value) {
+			continue
+		}
+		call(sub, clone(old), clone(value))
+	}
+}
+
+// call runs a subscriber, keeping a panicking one from breaking the reload.
+func call(sub *subscription, old, new interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Logger.Errorw("Configuration change handler panicked", "key", sub.key, "panic", r)
+		}
+	}()
+	sub.fn(old, new)
+}
+
+// Reload re-reads the .env and config files, or the cache file when the
+// configuration is cached, and atomically swaps in the new configuration.
+// Invalid configuration is rejected and the current one stays active.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	LoadConfig()
+
+	restore := func() {}
+	if !IsCached() {
+		var err error
+		if restore, err = refreshEnv(); err != nil {
+			logger.Logger.Errorw("Failed to reload .env files, keeping the current configuration", "error", err)
+			return err
+		}
+	}
+
+	if _, err := Load(); err != nil {
+		restore()
+		logger.Logger.Errorw("Rejected configuration reload, keeping the current configuration", "error", err)
+		return err
+	}
+
+	logger.Logger.Infow("Configuration reloaded")
+	return nil
+}
+
+// refreshEnv re-reads the .env files into the values lookupEnv reads, in
+// place of the ones loaded before. The process environment is left as it
+// is, and variables from the real environment keep precedence. The returned
+// function puts the previous values back.
+func refreshEnv() (func(), error) {
+	entries, files, err := readEnvFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	origins := make(map[string]string, len(entries))
+	for key, value := range interpolate(entries) {
+		if _, ok := realEnv(key); ok {
+			continue
+		}
+		values[key] = value
+		origins[key] = entries[key].file
+	}
+
+	envMu.Lock()
+	defer envMu.Unlock()
+	previousValues, previousOrigins, previousFiles := envValues, envOrigins, envFiles
+	envValues, envOrigins, envFiles = values, origins, files
+
+	return func() {
+		envMu.Lock()
+		defer envMu.Unlock()
+		envValues, envOrigins, envFiles = previousValues, previousOrigins, previousFiles
+	}, nil
+}
+
+// Watch reloads the configuration on SIGHUP and, when interval is positive,
+// whenever one of its files changes, until ctx is cancelled.
+func Watch(ctx context.Context, interval time.Duration) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	logger.Logger.Infow("Watching configuration for changes", "interval", interval)
+	fingerprint := watchedFingerprint()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			logger.Logger.Infow("Received SIGHUP, reloading configuration")
+			Reload()
+			fingerprint = watchedFingerprint()
+		case <-tick:
+			next := watchedFingerprint()
+			if next == fingerprint {
+				continue
+			}
+			fingerprint = next
+			Reload()
+		}
+	}
+}
+
+// watchedFingerprint summarizes the modification time and size of every file
+// the configuration is read from, including files that do not exist yet.
+func watchedFingerprint() string {
+	base := EnvFilePath()
+	files := []string{base, base + ".local", CachePath()}
+	if env, _ := getenv("APP_ENV"); env != "" {
+		files = append(files, base+"."+env)
+	}
+
+	dir := configPath()
+	files = append(files, dir)
+	if entries, err := os.ReadDir(dir); err == nil {
+		for _, entry := range entries {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	fingerprint := ""
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fingerprint += fmt.Sprintf("%s:%d:%d;", file, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return fingerprint
+}
@@ -0,0 +1,286 @@
+package configs
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"jazz/backend/pkg/logger"
+)
+
+// snapshot is an immutable, fully resolved configuration: the dot-notation
+// tree, an index of every key in it and the typed structs decoded from it.
+type snapshot struct {
+	tree   map[string]interface{}
+	index  map[string]interface{}
+	config *Configuration
+}
+
+// override is a value changed at runtime with Set.
+type override struct {
+	key   string
+	value interface{}
+}
+
+var (
+	state     atomic.Pointer[snapshot]
+	writeMu   sync.Mutex
+	overrides []override
+)
+
+// resolve applies the runtime overrides to a raw tree and decodes it.
+func resolve(raw map[string]interface{}) (*snapshot, error) {
+	tree := clone(raw).(map[string]interface{})
+	for _, o := range overrides {
+		setPath(tree, o.key, o.value)
+	}
+
+	cfg, err := parse(tree)
+	if err != nil {
+		return nil, err
+	}
+	return newSnapshot(tree, cfg), nil
+}
+
+// newSnapshot overlays the typed values on the raw tree, so lookups return
+// parsed values while keys unknown to the structs stay reachable.
+func newSnapshot(tree map[string]interface{}, cfg *Configuration) *snapshot {
+	merged := merge(tree, encode(reflect.ValueOf(cfg).Elem())).(map[string]interface{})
+
+	// Index every key up front so lookups never walk the tree.
+	index := make(map[string]interface{})
+	flatten("", merged, index)
+
+	return &snapshot{tree: merged, index: index, config: cfg}
+}
+
+// flatten records value and everything below it in index under dot-notation keys.
+func flatten(key string, value interface{}, index map[string]interface{}) {
+	index[key] = value
+	if m, ok := toMap(value); ok {
+		for k, v := range m {
+			flatten(join(key, k), v, index)
+		}
+		return
+	}
+	if _, isString := value.(string); isString {
+		return
+	}
+	if items, ok := toSlice(value); ok {
+		for i, item := range items {
+			flatten(join(key, strconv.Itoa(i)), item, index)
+		}
+	}
+}
+
+// get returns the value at a dot-notation key.
+func (s *snapshot) get(key string) (interface{}, bool) {
+	value, ok := s.index[key]
+	return value, ok
+}
+
+// current returns the active snapshot, loading the configuration on first use.
+func current() *snapshot {
+	if snap := state.Load(); snap != nil {
+		return snap
+	}
+	if _, err := Load(); err != nil {
+		logger.Logger.Fatalw("Failed to load configuration", "error", err)
+	}
+	return state.Load()
+}
+
+// Config returns the value at a dot-notation key such as
+// "database.connections.pgsql.host", or defaultValue when it is not set.
+func Config(key string, defaultValue ...interface{}) interface{} {
+	if value, ok := current().get(key); ok {
+		return clone(value)
+	}
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return nil
+}
+
+// Has reports whether a dot-notation key is set.
+func Has(key string) bool {
+	_, ok := current().get(key)
+	return ok
+}
+
+// Set overrides the value at a dot-notation key for the rest of the process,
+// including across reloads. It fails when the value does not fit the typed
+// configuration or makes it invalid, leaving the current one untouched.
+func Set(key string, value interface{}) error {
+	current()
+
+	writeMu.Lock()
+	tree := clone(state.Load().tree).(map[string]interface{})
+	setPath(tree, key, value)
+
+	cfg, err := parse(tree)
+	if err != nil {
+		writeMu.Unlock()
+		return err
+	}
+
+	overrides = append(overrides, override{key: key, value: value})
+	snap := newSnapshot(tree, cfg)
+	previous := state.Swap(snap)
+	writeMu.Unlock()
+
+	notify(previous, snap)
+	return nil
+}
+
+// ClearOverrides drops every value changed with Set and reloads the configuration.
+func ClearOverrides() error {
+	writeMu.Lock()
+	overrides = nil
+	writeMu.Unlock()
+
+	_, err := Load()
+	return err
+}
+
+// String returns the value at key as a string.
+func String(key string, defaultValue ...string) string {
+	if value, ok := current().get(key); ok && value != nil {
+		return toString(value)
+	}
+	return first(defaultValue)
+}
+
+// Int returns the value at key as an int.
+func Int(key string, defaultValue ...int) int {
+	if value, ok := current().get(key); ok && value != nil {
+		if n, err := toInt(value); err == nil {
+			return int(n)
+		}
+	}
+	return first(defaultValue)
+}
+
+// Bool returns the value at key as a bool.
+func Bool(key string, defaultValue ...bool) bool {
+	if value, ok := current().get(key); ok && value != nil {
+		if b, err := toBool(value); err == nil {
+			return b
+		}
+	}
+	return first(defaultValue)
+}
+
+// Duration returns the value at key as a time.Duration. Bare numbers are seconds.
+func Duration(key string, defaultValue ...time.Duration) time.Duration {
+	if value, ok := current().get(key); ok && value != nil {
+		if d, err := toDuration(value); err == nil {
+			return d
+		}
+	}
+	return first(defaultValue)
+}
+
+// StringSlice returns the value at key as a list of strings. Strings are split on commas.
+func StringSlice(key string, defaultValue ...[]string) []string {
+	if value, ok := current().get(key); ok && value != nil {
+		if items, ok := toSlice(value); ok {
+			list := make([]string, len(items))
+			for i, item := range items {
+				list[i] = toString(item)
+			}
+			return list
+		}
+	}
+	return first(defaultValue)
+}
+
+// first returns the first of the optional default values, or the zero value.
+func first[T any](values []T) T {
+	var zero T
+	if len(values) > 0 {
+		return values[0]
+	}
+	return zero
+}
+
+// setPath stores value at a dot-notation key, creating intermediate maps as
+// needed. A numeric segment indexes into an existing list.
+func setPath(tree map[string]interface{}, key string, value interface{}) {
+	setIn(tree, strings.Split(key, "."), value)
+}
+
+// setIn stores value below node and returns node, or the copy toMap or
+// toSlice converted it to, for the parent to store back.
+func setIn(node interface{}, segments []string, value interface{}) interface{} {
+	if len(segments) == 0 {
+		return value
+	}
+	segment := segments[0]
+
+	if _, isString := node.(string); !isString {
+		if items, ok := toSlice(node); ok {
+			if i, err := strconv.Atoi(segment); err == nil && i >= 0 && i < len(items) {
+				items[i] = setIn(items[i], segments[1:], value)
+				return items
+			}
+		}
+	}
+
+	m, ok := toMap(node)
+	if !ok {
+		m = map[string]interface{}{}
+	}
+	m[segment] = setIn(m[segment], segments[1:], value)
+	return m
+}
+
+// clone deep-copies the maps and lists of a tree so snapshots never share them.
+func clone(value interface{}) interface{} {
+	if m, ok := toMap(value); ok {
+		copied := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			copied[k] = clone(v)
+		}
+		return copied
+	}
+
+	switch value.(type) {
+	case string, []byte, nil:
+		return value
+	}
+	if reflect.ValueOf(value).Kind() == reflect.Slice {
+		items, _ := toSlice(value)
+		copied := make([]interface{}, len(items))
+		for i, item := range items {
+			copied[i] = clone(item)
+		}
+		return copied
+	}
+	return value
+}
+
+// merge overlays src on dst, recursing into maps present in both.
+func merge(dst, src interface{}) interface{} {
+	dstMap, dstIsMap := toMap(dst)
+	srcMap, srcIsMap := toMap(src)
+	if !dstIsMap || !srcIsMap {
+		return src
+	}
+
+	merged := make(map[string]interface{}, len(dstMap))
+	for k, v := range dstMap {
+		merged[k] = v
+	}
+	for k, v := range srcMap {
+		if existing, ok := merged[k]; ok {
+			merged[k] = merge(existing, v)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
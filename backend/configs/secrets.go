@@ -0,0 +1,205 @@
+package configs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretScheme prefixes configuration values that reference a secret, such as
+// "secret://vault/database/pgsql#password", "secret://file/db_password" or
+// "secret://env/DB_PASSWORD", which also reads DB_PASSWORD_FILE.
+const secretScheme = "secret://"
+
+// SecretProvider resolves secret references. path identifies the secret and
+// key selects a field within it; key is empty when the reference has no #key.
+type SecretProvider interface {
+	Resolve(ctx context.Context, path string, key string) (string, error)
+}
+
+var (
+	secretProviders   = map[string]SecretProvider{}
+	secretProvidersMu sync.RWMutex
+)
+
+// RegisterSecretProvider makes a provider available to secret://name/... references.
+func RegisterSecretProvider(name string, provider SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[name] = provider
+}
+
+// secretProvider returns the provider registered under name. The built-in
+// "env", "file" and "vault" providers are created from the environment on first use.
+func secretProvider(name string) (SecretProvider, error) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+
+	if provider, ok := secretProviders[name]; ok {
+		return provider, nil
+	}
+
+	var provider SecretProvider
+	switch name {
+	case "env":
+		provider = envSecretProvider{}
+	case "file":
+		provider = NewFileSecretProvider(GetWithDefault("JAZZ_SECRETS_PATH", "/run/secrets").(string))
+	case "vault":
+		addr := Get("VAULT_ADDR").(string)
+		if addr == "" {
+			return nil, fmt.Errorf("secret provider %q requires VAULT_ADDR", name)
+		}
+		provider = NewVaultSecretProvider(addr, Get("VAULT_TOKEN").(string), GetWithDefault("VAULT_KV_MOUNT", "secret").(string), Get("VAULT_NAMESPACE").(string))
+	default:
+		return nil, fmt.Errorf("secret provider %q is not registered", name)
+	}
+
+	secretProviders[name] = provider
+	return provider, nil
+}
+
+// resolveSecrets replaces every secret reference in a tree with its value. It
+// also returns the references by dot-notation key so they can be preserved.
+func resolveSecrets(tree map[string]interface{}) (map[string]interface{}, map[string]string, error) {
+	references := map[string]string{}
+	collectSecrets("", tree, references)
+	if len(references) == 0 {
+		return tree, references, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	keys := make([]string, 0, len(references))
+	for key := range references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	resolved := clone(tree).(map[string]interface{})
+	values := map[string]string{}
+	var problems []string
+	for _, key := range keys {
+		reference := references[key]
+		value, ok := values[reference]
+		if !ok {
+			var err error
+			if value, err = resolveSecret(ctx, reference); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+				continue
+			}
+			values[reference] = value
+		}
+		setPath(resolved, key, value)
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, &ValidationError{Problems: problems}
+	}
+	return resolved, references, nil
+}
+
+// collectSecrets records the dot-notation key of every secret reference
+// below value, indexing into lists.
+func collectSecrets(key string, value interface{}, references map[string]string) {
+	if m, ok := toMap(value); ok {
+		for k, v := range m {
+			collectSecrets(join(key, k), v, references)
+		}
+		return
+	}
+	if s, ok := value.(string); ok {
+		if strings.HasPrefix(s, secretScheme) {
+			references[key] = s
+		}
+		return
+	}
+	if items, ok := toSlice(value); ok {
+		for i, item := range items {
+			collectSecrets(join(key, strconv.Itoa(i)), item, references)
+		}
+	}
+}
+
+// resolveSecret fetches the value of a single secret:// reference.
+func resolveSecret(ctx context.Context, reference string) (string, error) {
+	location, key, _ := strings.Cut(strings.TrimPrefix(reference, secretScheme), "#")
+	name, path, found := strings.Cut(location, "/")
+	if !found || name == "" || path == "" {
+		return "", fmt.Errorf("invalid secret reference %q, expected secret://provider/path[#key]", reference)
+	}
+
+	provider, err := secretProvider(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := provider.Resolve(ctx, path, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", reference, err)
+	}
+	return value, nil
+}
+
+// readSecretFile returns the content of a mounted secret file without its trailing newline.
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// envSecretProvider reads secrets from environment variables, or from the
+// file named by KEY_FILE, so a cached configuration can refer to them.
+type envSecretProvider struct{}
+
+// Resolve returns the variable named by path.
+func (envSecretProvider) Resolve(ctx context.Context, path string, key string) (string, error) {
+	value, ok := lookupEnv(path)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", path)
+	}
+	return value, nil
+}
+
+// FileSecretProvider reads secrets from files in a directory, such as Docker
+// and Kubernetes secret mounts. A #key selects a field of a JSON file.
+type FileSecretProvider struct {
+	dir string
+}
+
+// NewFileSecretProvider creates a provider reading secrets from dir.
+func NewFileSecretProvider(dir string) *FileSecretProvider {
+	return &FileSecretProvider{dir: dir}
+}
+
+// Resolve reads the secret file at path within the provider's directory.
+func (p *FileSecretProvider) Resolve(ctx context.Context, path string, key string) (string, error) {
+	file := filepath.Join(p.dir, filepath.Clean("/"+path))
+	value, err := readSecretFile(file)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return value, nil
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret file %s is not a JSON object: %w", file, err)
+	}
+	field, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret file %s has no key %q", file, key)
+	}
+	return toString(field), nil
+}
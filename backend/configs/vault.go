@@ -0,0 +1,76 @@
+package configs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultSecretProvider reads secrets from a HashiCorp Vault KV version 2
+// engine over its HTTP API.
+type VaultSecretProvider struct {
+	addr      string
+	token     string
+	mount     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVaultSecretProvider creates a provider for the KV engine mounted at mount.
+func NewVaultSecretProvider(addr, token, mount, namespace string) *VaultSecretProvider {
+	return &VaultSecretProvider{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		mount:     strings.Trim(mount, "/"),
+		namespace: namespace,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Resolve reads the field key of the secret at path. Without a key the field
+// named "value" is used.
+func (p *VaultSecretProvider) Resolve(ctx context.Context, path string, key string) (string, error) {
+	if key == "" {
+		key = "value"
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", p.addr, p.mount, strings.Trim(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	value, ok := payload.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no key %q", path, key)
+	}
+	return toString(value), nil
+}
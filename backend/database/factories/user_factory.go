@@ -0,0 +1,41 @@
+// Package factories defines the fake data factories of the application's models.
+package factories
+
+import (
+	"sync"
+
+	"jazz/backend/models"
+	"jazz/backend/pkg/factory"
+	"jazz/backend/pkg/faker"
+	"jazz/backend/pkg/hashing"
+	"jazz/backend/pkg/logger"
+)
+
+// DefaultPassword is the password of every user the factory makes.
+const DefaultPassword = "password"
+
+var (
+	passwordHash     string
+	passwordHashOnce sync.Once
+)
+
+func init() {
+	factory.Define(func(f *faker.Faker) models.User {
+		return models.User{
+			Username: f.Unique("username", (*faker.Faker).Username),
+			Password: hashedPassword(),
+		}
+	})
+}
+
+// hashedPassword hashes DefaultPassword once, as hashing it for every user
+// would make seeding slow.
+func hashedPassword() string {
+	passwordHashOnce.Do(func() {
+		var err error
+		if passwordHash, err = hashing.Make(DefaultPassword); err != nil {
+			logger.Logger.Errorw("Failed to hash the factory password", "error", err)
+		}
+	})
+	return passwordHash
+}
@@ -1,22 +1,28 @@
 package migrations
 
 import (
-	"fmt"
-	"jazz/backend/models"
+	"jazz/backend/pkg/migration"
+	"jazz/backend/pkg/schema"
 
 	"gorm.io/gorm"
 )
 
-// Up is executed when this migration is applied
-func Up(db *gorm.DB) {
-	fmt.Println("Applying migration: create sample table")
-	// Automigrate the model
-	db.AutoMigrate(&models.User{})
-}
-
-// Down is executed when this migration is reverted
-func Down(db *gorm.DB) {
-	fmt.Println("Reverting migration: drop sample table")
-	// Drop the table associated with the model
-	db.Migrator().DropTable(&models.User{})
+func init() {
+	migration.Register(migration.Migration{
+		Name: "20241017014919_create_user_table",
+		// Up is executed when this migration is applied
+		Up: func(tx *gorm.DB) error {
+			return schema.Create(tx, "users", func(t *schema.Blueprint) {
+				t.ID()
+				t.Timestamps()
+				t.SoftDeletes()
+				t.String("username").Unique()
+				t.String("password")
+			})
+		},
+		// Down is executed when this migration is reverted
+		Down: func(tx *gorm.DB) error {
+			return schema.DropIfExists(tx, "users")
+		},
+	})
 }
@@ -0,0 +1,17 @@
+package seeders
+
+import "gorm.io/gorm"
+
+// DatabaseSeeder runs every seeder of the application.
+type DatabaseSeeder struct{}
+
+func init() {
+	Register(DatabaseSeeder{})
+}
+
+// Run seeds the demo data.
+func (DatabaseSeeder) Run(db *gorm.DB) error {
+	return Call(db,
+		UserSeeder{},
+	)
+}
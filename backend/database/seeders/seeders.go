@@ -0,0 +1,70 @@
+// Package seeders fills the database with demo data, similar to Laravel's
+// database seeders. DatabaseSeeder is the root seeder run by db:seed.
+package seeders
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"jazz/backend/pkg/logger"
+
+	// Registers the model factories the seeders use
+	_ "jazz/backend/database/factories"
+
+	"gorm.io/gorm"
+)
+
+// Seeder inserts data into the database.
+type Seeder interface {
+	Run(db *gorm.DB) error
+}
+
+var (
+	registry   = map[string]Seeder{}
+	registryMu sync.RWMutex
+)
+
+// Register makes a seeder available to db:seed --class under its type name.
+func Register(s Seeder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name(s)] = s
+}
+
+// Find returns the seeder registered under name.
+func Find(name string) (Seeder, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if s, ok := registry[name]; ok {
+		return s, nil
+	}
+
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("seeder %q is not registered, available seeders: %v", name, names)
+}
+
+// Call runs seeders in order, stopping at the first error.
+func Call(db *gorm.DB, seeders ...Seeder) error {
+	for _, s := range seeders {
+		logger.Logger.Infow("Seeding", "seeder", name(s))
+		if err := s.Run(db); err != nil {
+			return fmt.Errorf("%s: %w", name(s), err)
+		}
+	}
+	return nil
+}
+
+// name returns the type name of a seeder.
+func name(s Seeder) string {
+	t := reflect.TypeOf(s)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
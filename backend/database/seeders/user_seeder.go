@@ -0,0 +1,45 @@
+package seeders
+
+import (
+	"jazz/backend/models"
+	"jazz/backend/pkg/factory"
+	"jazz/backend/pkg/faker"
+
+	"gorm.io/gorm"
+)
+
+// UserSeeder creates a demo user to log in with and a crowd of fake users.
+type UserSeeder struct{}
+
+func init() {
+	Register(UserSeeder{})
+}
+
+// Run creates the users. The demo user logs in as demo / password.
+func (UserSeeder) Run(db *gorm.DB) error {
+	demo, err := factory.New[models.User]().MakeOne()
+	if err != nil {
+		return err
+	}
+	demo.Username = "demo"
+	if err := db.Where(models.User{Username: demo.Username}).FirstOrCreate(&demo).Error; err != nil {
+		return err
+	}
+
+	// Skip the usernames of earlier runs, as the batch fails on any duplicate
+	var usernames []string
+	if err := db.Model(&models.User{}).Pluck("username", &usernames).Error; err != nil {
+		return err
+	}
+	taken := make(map[string]bool, len(usernames))
+	for _, username := range usernames {
+		taken[username] = true
+	}
+
+	_, err = factory.New[models.User]().Count(50).State(func(u *models.User, f *faker.Faker) {
+		for taken[u.Username] {
+			u.Username = f.Unique("username", (*faker.Faker).Username)
+		}
+	}).Create(db)
+	return err
+}
@@ -3,14 +3,28 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"jazz/backend/models"
 	"jazz/backend/pkg/auth"
 	"jazz/backend/pkg/database"
+	"jazz/backend/pkg/logger"
 )
 
+// userRepository returns the users repository, answering 503 Service
+// Unavailable when the database cannot be reached.
+func userRepository(w http.ResponseWriter) (*database.Repository[models.User], bool) {
+	db, err := database.DefaultConnection()
+	if err != nil {
+		logger.Logger.Errorw("Database is unavailable", "error", err)
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return database.NewRepository[models.User](db), true
+}
+
 // RegisterUserHandler handles user registration.
 func RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 	var user models.User
@@ -24,8 +38,11 @@ func RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db := database.GetDBInstance()
-	if err := db.Create(&user).Error; err != nil {
+	users, ok := userRepository(w)
+	if !ok {
+		return
+	}
+	if err := users.Create(r.Context(), &user); err != nil {
 		http.Error(w, "Error creating user", http.StatusInternalServerError)
 		return
 	}
@@ -45,18 +62,35 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user models.User
-	db := database.GetDBInstance()
-	if err := db.Where("username = ?", credentials.Username).First(&user).Error; err != nil {
+	users, ok := userRepository(w)
+	if !ok {
+		return
+	}
+	user, err := users.FindBy(r.Context(), "username", credentials.Username)
+	if errors.Is(err, database.ErrNotFound) {
 		http.Error(w, "User not found", http.StatusUnauthorized)
 		return
 	}
+	if err != nil {
+		http.Error(w, "Error finding user", http.StatusInternalServerError)
+		return
+	}
 
 	if err := user.VerifyPassword(credentials.Password); err != nil {
 		http.Error(w, "Invalid password", http.StatusUnauthorized)
 		return
 	}
 
+	// Refresh the stored hash when the hashing driver or its parameters changed
+	if user.PasswordNeedsRehash() {
+		user.Password = credentials.Password
+		if err := user.HashPassword(); err != nil {
+			logger.Logger.Errorw("Failed to rehash password", "user_id", user.ID, "error", err)
+		} else if err := users.Update(r.Context(), user, map[string]interface{}{"password": user.Password}); err != nil {
+			logger.Logger.Errorw("Failed to store rehashed password", "user_id", user.ID, "error", err)
+		}
+	}
+
 	token, err := auth.GenerateJWT(user.ID)
 	if err != nil {
 		http.Error(w, "Error generating token", http.StatusInternalServerError)
@@ -0,0 +1,289 @@
+package models
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"jazz/backend/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// An observer of models of type T implements any of the following
+// interfaces. The tx given to a callback runs in the statement's
+// transaction and context.
+//
+// Creating, Updating and Deleting run before the write, and an error cancels
+// it. Created, Updated, Deleted and Restored run after the write, before its
+// transaction commits, and an error rolls it back. Side effects outside the
+// database belong in database.AfterCommit(tx.Statement.Context, ...), which
+// waits for the outermost commit. Updates and deletes only notify observers
+// of loaded models, not of mass operations such as
+// db.Where(...).Delete(&User{}).
+type (
+	Creating[T any] interface {
+		Creating(tx *gorm.DB, model *T) error
+	}
+	Created[T any] interface {
+		Created(tx *gorm.DB, model *T) error
+	}
+	Updating[T any] interface {
+		Updating(tx *gorm.DB, model *T) error
+	}
+	Updated[T any] interface {
+		Updated(tx *gorm.DB, model *T) error
+	}
+	Deleting[T any] interface {
+		Deleting(tx *gorm.DB, model *T) error
+	}
+	Deleted[T any] interface {
+		Deleted(tx *gorm.DB, model *T) error
+	}
+	// Restored runs after an unscoped update clearing a soft deleted model's
+	// DeletedAt, as well as Updated.
+	Restored[T any] interface {
+		Restored(tx *gorm.DB, model *T) error
+	}
+)
+
+// event names a model lifecycle event.
+type event string
+
+const (
+	creating event = "creating"
+	created  event = "created"
+	updating event = "updating"
+	updated  event = "updated"
+	deleting event = "deleting"
+	deleted  event = "deleted"
+	restored event = "restored"
+)
+
+// handler calls one observer method with a *T.
+type handler func(tx *gorm.DB, model interface{}) error
+
+var (
+	observers   = map[reflect.Type]map[event][]handler{}
+	observersMu sync.RWMutex
+)
+
+func init() {
+	if err := database.RegisterPlugin(observerPlugin{}); err != nil {
+		panic(err)
+	}
+}
+
+// Observe registers observer for the models of model's type. It panics when
+// observer implements none of the observer interfaces for that type.
+//
+//	models.Observe(&models.User{}, UserObserver{})
+func Observe[T any](model *T, observer interface{}) {
+	handlers := map[event][]handler{}
+	on := func(e event, fn func(tx *gorm.DB, model *T) error) {
+		handlers[e] = append(handlers[e], func(tx *gorm.DB, model interface{}) error {
+			return fn(tx, model.(*T))
+		})
+	}
+	if o, ok := observer.(Creating[T]); ok {
+		on(creating, o.Creating)
+	}
+	if o, ok := observer.(Created[T]); ok {
+		on(created, o.Created)
+	}
+	if o, ok := observer.(Updating[T]); ok {
+		on(updating, o.Updating)
+	}
+	if o, ok := observer.(Updated[T]); ok {
+		on(updated, o.Updated)
+	}
+	if o, ok := observer.(Deleting[T]); ok {
+		on(deleting, o.Deleting)
+	}
+	if o, ok := observer.(Deleted[T]); ok {
+		on(deleted, o.Deleted)
+	}
+	if o, ok := observer.(Restored[T]); ok {
+		on(restored, o.Restored)
+	}
+	if len(handlers) == 0 {
+		panic(fmt.Sprintf("models: %T observes no event of %T", observer, model))
+	}
+
+	observersMu.Lock()
+	defer observersMu.Unlock()
+	modelType := reflect.TypeFor[T]()
+	if observers[modelType] == nil {
+		observers[modelType] = map[event][]handler{}
+	}
+	for e, hs := range handlers {
+		observers[modelType][e] = append(observers[modelType][e], hs...)
+	}
+}
+
+// observerPlugin notifies the observers from GORM callbacks, so models need
+// no hook methods of their own.
+type observerPlugin struct{}
+
+func (observerPlugin) Name() string {
+	return "models:observers"
+}
+
+func (observerPlugin) Initialize(db *gorm.DB) error {
+	// After events run within the statement's transaction, so their errors
+	// roll the write back instead of reporting a committed one as failed.
+	// AfterCommit waits for that transaction to commit.
+	const begun, committed = "gorm:begin_transaction", "gorm:commit_or_rollback_transaction"
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After(begun).Before("gorm:before_create").Register("models:defer_after_commit", deferAfterCommit),
+		callbacks.Create().Before("gorm:create").Register("models:creating", notify(creating)),
+		callbacks.Create().After("gorm:after_create").Before(committed).Register("models:created", notify(created)),
+		callbacks.Create().After(committed).Register("models:after_commit", runAfterCommit),
+		callbacks.Update().After(begun).Before("gorm:before_update").Register("models:defer_after_commit", deferAfterCommit),
+		callbacks.Update().Before("gorm:update").Register("models:updating", notify(updating)),
+		callbacks.Update().After("gorm:after_update").Before(committed).Register("models:updated", notify(updated)),
+		callbacks.Update().After(committed).Register("models:after_commit", runAfterCommit),
+		callbacks.Delete().After(begun).Before("gorm:before_delete").Register("models:defer_after_commit", deferAfterCommit),
+		callbacks.Delete().Before("gorm:delete").Register("models:deleting", notify(deleting)),
+		callbacks.Delete().After("gorm:after_delete").Before(committed).Register("models:deleted", notify(deleted)),
+		callbacks.Delete().After(committed).Register("models:after_commit", runAfterCommit),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterCommitKey is the instance setting holding the functions queued by
+// AfterCommit during a statement that began its own transaction.
+const afterCommitKey = "models:after_commit"
+
+// deferred is the statement's context before deferAfterCommit and the
+// function running what AfterCommit queued since.
+type deferred struct {
+	ctx context.Context
+	run func()
+}
+
+// deferAfterCommit makes AfterCommit wait for the transaction the statement
+// began, which observers and nested statements run in.
+func deferAfterCommit(tx *gorm.DB) {
+	if _, ok := tx.InstanceGet("gorm:started_transaction"); !ok {
+		return
+	}
+	ctx, run := database.DeferAfterCommit(tx.Statement.Context)
+	tx.InstanceSet(afterCommitKey, deferred{ctx: tx.Statement.Context, run: run})
+	tx.Statement.Context = ctx
+}
+
+// runAfterCommit runs the functions queued during the statement once its
+// transaction committed.
+func runAfterCommit(tx *gorm.DB) {
+	if d, ok := tx.InstanceGet(afterCommitKey); ok {
+		tx.Statement.Context = d.(deferred).ctx
+		if tx.Error == nil {
+			d.(deferred).run()
+		}
+	}
+}
+
+// notify returns the callback calling the observers of e.
+func notify(e event) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+		observersMu.RLock()
+		registered := observers[tx.Statement.Schema.ModelType]
+		observersMu.RUnlock()
+
+		events := []event{e}
+		if e == updated && restoring(tx) {
+			events = append(events, restored)
+		}
+		for _, e := range events {
+			if len(registered[e]) == 0 || (isAfter(e) && tx.Statement.RowsAffected == 0) {
+				continue
+			}
+			session := tx.Session(&gorm.Session{NewDB: true})
+			for _, model := range affected(tx, e == creating || e == created) {
+				for _, h := range registered[e] {
+					if err := h(session, model); err != nil {
+						tx.AddError(err)
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func isAfter(e event) bool {
+	return e == created || e == updated || e == deleted || e == restored
+}
+
+// affected returns pointers to the models a statement writes. Unless
+// creating, models without a primary key are skipped, as they only describe
+// a mass update or delete.
+func affected(tx *gorm.DB, creating bool) []interface{} {
+	var values []reflect.Value
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			values = append(values, rv.Index(i))
+		}
+	case reflect.Struct:
+		values = append(values, rv)
+	}
+
+	schema := tx.Statement.Schema
+	var models []interface{}
+	for _, v := range values {
+		if v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		if v.Type() != schema.ModelType || !v.CanAddr() {
+			continue
+		}
+		if pk := schema.PrioritizedPrimaryField; !creating && pk != nil {
+			if _, zero := pk.ValueOf(tx.Statement.Context, v); zero {
+				continue
+			}
+		}
+		models = append(models, v.Addr().Interface())
+	}
+	return models
+}
+
+// restoring reports whether an update cleared the model's gorm.DeletedAt,
+// as in db.Unscoped().Model(&user).Update("deleted_at", nil).
+func restoring(tx *gorm.DB) bool {
+	values, ok := tx.Statement.Dest.(map[string]interface{})
+	if !tx.Statement.Unscoped || !ok {
+		return false
+	}
+	for _, field := range tx.Statement.Schema.Fields {
+		if field.FieldType != reflect.TypeFor[gorm.DeletedAt]() {
+			continue
+		}
+		for _, column := range []string{field.DBName, field.Name} {
+			if value, ok := values[column]; ok && isNull(value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNull(value interface{}) bool {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		return err == nil && v == nil
+	}
+	return value == nil
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"jazz/backend/pkg/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type post struct {
+	ID        uint
+	Title     string
+	DeletedAt gorm.DeletedAt
+}
+
+// postObserver records the events it receives and refuses untitled posts.
+type postObserver struct {
+	events *[]string
+}
+
+func (o postObserver) record(event string, p *post) {
+	*o.events = append(*o.events, event+" "+p.Title)
+}
+
+func (o postObserver) Creating(tx *gorm.DB, p *post) error {
+	if p.Title == "" {
+		return errors.New("a post needs a title")
+	}
+	o.record("creating", p)
+	return nil
+}
+
+func (o postObserver) Created(tx *gorm.DB, p *post) error {
+	if p.ID == 0 {
+		return errors.New("expected the post to have an ID")
+	}
+	o.record("created", p)
+	return nil
+}
+
+func (o postObserver) Updated(tx *gorm.DB, p *post) error  { o.record("updated", p); return nil }
+func (o postObserver) Deleting(tx *gorm.DB, p *post) error { o.record("deleting", p); return nil }
+func (o postObserver) Deleted(tx *gorm.DB, p *post) error  { o.record("deleted", p); return nil }
+func (o postObserver) Restored(tx *gorm.DB, p *post) error { o.record("restored", p); return nil }
+
+func TestObserversReceiveLifecycleEvents(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "observer.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.Use(observerPlugin{}); err != nil {
+		t.Fatalf("Failed to install the plugin: %v", err)
+	}
+	db.AutoMigrate(&post{})
+
+	var events []string
+	Observe(&post{}, postObserver{events: &events})
+	t.Cleanup(func() {
+		observersMu.Lock()
+		delete(observers, reflect.TypeFor[post]())
+		observersMu.Unlock()
+	})
+
+	if err := db.Create(&post{}).Error; err == nil {
+		t.Error("Expected the creating observer to cancel the insert")
+	}
+	var count int64
+	if db.Model(&post{}).Count(&count); count != 0 {
+		t.Errorf("Expected no post to be created, got %d", count)
+	}
+
+	posts := []post{{Title: "first"}, {Title: "second"}}
+	db.Create(&posts)
+	first := posts[0]
+	db.Model(&first).Update("title", "renamed")
+	db.Where("title = ?", "second").Delete(&post{})
+	db.Delete(&first)
+	db.Unscoped().Model(&first).Update("deleted_at", nil)
+
+	want := []string{
+		"creating first", "creating second", "created first", "created second",
+		"updated renamed",
+		"deleting renamed", "deleted renamed",
+		"updated renamed", "restored renamed",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected events\n%q\ngot\n%q", want, events)
+	}
+}
+
+type note struct {
+	ID   uint
+	Body string
+}
+
+// noteObserver refuses every update once it was written, and records what
+// it defers until the commit.
+type noteObserver struct {
+	committed *[]string
+}
+
+func (o noteObserver) Created(tx *gorm.DB, n *note) error {
+	database.AfterCommit(tx.Statement.Context, func() { *o.committed = append(*o.committed, "created") })
+	return nil
+}
+
+func (o noteObserver) Updated(tx *gorm.DB, n *note) error {
+	database.AfterCommit(tx.Statement.Context, func() { *o.committed = append(*o.committed, "updated") })
+	return errors.New("notes are read-only")
+}
+
+func TestAfterObserverErrorsRollBackTheWrite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "observer.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.Use(observerPlugin{}); err != nil {
+		t.Fatalf("Failed to install the plugin: %v", err)
+	}
+	db.AutoMigrate(&note{})
+
+	var committed []string
+	Observe(&note{}, noteObserver{committed: &committed})
+	t.Cleanup(func() {
+		observersMu.Lock()
+		delete(observers, reflect.TypeFor[note]())
+		observersMu.Unlock()
+	})
+
+	n := note{Body: "original"}
+	db.Create(&n)
+	if err := db.Model(&n).Update("body", "changed").Error; err == nil {
+		t.Error("Expected the updated observer's error")
+	}
+
+	var stored note
+	db.First(&stored, n.ID)
+	if stored.Body != "original" {
+		t.Errorf("Expected the update to be rolled back, got %q", stored.Body)
+	}
+	if !reflect.DeepEqual(committed, []string{"created"}) {
+		t.Errorf("Expected only the committed create to run its AfterCommit, got %q", committed)
+	}
+}
+
+func TestObserveRejectsObserversWithoutEvents(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Observe to panic")
+		}
+	}()
+	Observe(&post{}, struct{}{})
+}
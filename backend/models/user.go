@@ -1,7 +1,8 @@
 package models
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"jazz/backend/pkg/hashing"
+
 	"gorm.io/gorm"
 )
 
@@ -12,17 +13,26 @@ type User struct {
 	Password string `gorm:"not null"`
 }
 
-// HashPassword hashes the user's password.
+// HashPassword hashes the user's password with the configured hashing driver.
 func (u *User) HashPassword() error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+	hashedPassword, err := hashing.Make(u.Password)
 	if err != nil {
 		return err
 	}
-	u.Password = string(hashedPassword)
+	u.Password = hashedPassword
 	return nil
 }
 
 // VerifyPassword verifies the user's password.
 func (u *User) VerifyPassword(password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
+	if !hashing.Check(password, u.Password) {
+		return hashing.ErrMismatchedPassword
+	}
+	return nil
+}
+
+// PasswordNeedsRehash reports whether the stored hash was made with another
+// driver or outdated parameters.
+func (u *User) PasswordNeedsRehash() bool {
+	return hashing.NeedsRehash(u.Password)
 }
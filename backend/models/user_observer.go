@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+
+	"jazz/backend/pkg/cache"
+	"jazz/backend/pkg/database"
+	"jazz/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	Observe(&User{}, UserObserver{})
+}
+
+// UserCacheKey is the cache key of anything cached about a user, forgotten
+// whenever the user changes.
+func UserCacheKey(id uint) string {
+	return fmt.Sprintf("users:%d", id)
+}
+
+// UserObserver forgets cached users and logs whenever a user changes, once
+// the change is committed.
+type UserObserver struct{}
+
+func (UserObserver) Created(tx *gorm.DB, user *User) error {
+	logChange(tx, "created", user)
+	return nil
+}
+
+func (UserObserver) Updated(tx *gorm.DB, user *User) error {
+	forget(tx, user)
+	logChange(tx, "updated", user)
+	return nil
+}
+
+func (UserObserver) Deleted(tx *gorm.DB, user *User) error {
+	forget(tx, user)
+	logChange(tx, "deleted", user)
+	return nil
+}
+
+func (UserObserver) Restored(tx *gorm.DB, user *User) error {
+	logChange(tx, "restored", user)
+	return nil
+}
+
+func forget(tx *gorm.DB, user *User) {
+	id := user.ID
+	database.AfterCommit(tx.Statement.Context, func() {
+		if err := cache.NewCacheManager().Forget(UserCacheKey(id)); err != nil {
+			logger.Logger.Warnw("Failed to forget cached user", "user_id", id, "error", err)
+		}
+	})
+}
+
+func logChange(tx *gorm.DB, action string, user *User) {
+	id, username := user.ID, user.Username
+	database.AfterCommit(tx.Statement.Context, func() {
+		logger.Logger.Infow("User changed", "action", action, "user_id", id, "username", username)
+	})
+}
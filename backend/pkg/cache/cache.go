@@ -9,3 +9,11 @@ type Cache interface {
 	Forget(key string) error
 	Remember(key string, expiration time.Duration, callback func() (interface{}, error)) (interface{}, error)
 }
+
+// Incrementer is implemented by the stores shared between processes.
+// Increment adds delta to the integer counter at key in a single atomic
+// operation, creating the counter with the given expiration when it does
+// not exist, and returns the new value.
+type Incrementer interface {
+	Increment(key string, delta int64, expiration time.Duration) (int64, error)
+}
@@ -1,8 +1,8 @@
 package cache
 
 import (
+	"jazz/backend/configs"
 	"jazz/backend/pkg/logger"
-	"os"
 	"sync"
 )
 
@@ -14,7 +14,8 @@ var (
 // NewCacheManager returns an implementation of Cache based on the configuration driver.
 func NewCacheManager() Cache {
 	cacheOnce.Do(func() {
-		driver := os.Getenv("CACHE_DRIVER")
+		cacheConfig := configs.Cache()
+		driver := cacheConfig.Stores[cacheConfig.Default].Driver
 
 		switch driver {
 		case "redis":
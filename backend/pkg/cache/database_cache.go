@@ -3,12 +3,14 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"jazz/backend/pkg/database"
 	"jazz/backend/pkg/logger"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CacheEntry represents a cache entry in the database.
@@ -29,11 +31,9 @@ func NewDatabaseCache() Cache {
 	logger.InitializeLogger()
 
 	// Get the database instance from the database module
-	db := database.GetDBInstance()
-
-	// Ensure db is not nil
-	if db == nil {
-		logger.Logger.Fatal("Database connection is nil after initialization.")
+	db, err := database.DefaultConnection()
+	if err != nil {
+		logger.Logger.Fatal(fmt.Sprintf("Failed to open the database connection: %v", err))
 	}
 
 	// Check if the table exists and create it if necessary
@@ -46,7 +46,7 @@ func NewDatabaseCache() Cache {
 	}
 
 	// Perform migration to ensure table structure is updated if needed
-	err := db.AutoMigrate(&CacheEntry{})
+	err = db.AutoMigrate(&CacheEntry{})
 	if err != nil {
 		logger.Logger.Fatal(fmt.Sprintf("Failed to migrate table 'cache_entries': %v", err))
 	}
@@ -144,3 +144,40 @@ func (d *DatabaseCache) Get(key string) (interface{}, error) {
 
 	return value, nil
 }
+
+// Increment atomically adds delta to the counter at key. The row is created
+// if needed and locked for the update, so concurrent increments queue up.
+func (d *DatabaseCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	if d.db == nil {
+		return 0, fmt.Errorf("database connection is not initialized")
+	}
+
+	var value int64
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		created := CacheEntry{Key: key, Value: "0", Expiration: now.Add(expiration).Unix()}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&created).Error; err != nil {
+			return err
+		}
+
+		var entry CacheEntry
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&CacheEntry{Key: key}).First(&entry).Error; err != nil {
+			return err
+		}
+		if now.Unix() > entry.Expiration {
+			entry.Value, entry.Expiration = "0", created.Expiration
+		}
+		current, err := strconv.ParseInt(entry.Value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cache entry %q is not a counter", key)
+		}
+
+		value = current + delta
+		entry.Value = strconv.FormatInt(value, 10)
+		return tx.Save(&entry).Error
+	})
+	if err != nil {
+		logger.Logger.Errorw("Failed to increment value in database cache", "key", key, "error", err)
+	}
+	return value, err
+}
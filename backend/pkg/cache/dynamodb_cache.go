@@ -3,6 +3,7 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"jazz/backend/configs"
@@ -22,12 +23,12 @@ type DynamoDBCache struct {
 
 // NewDynamoDBCache initializes a new DynamoDB Cache.
 func NewDynamoDBCache() *DynamoDBCache {
-	cacheConfig := configs.GetCacheConfig()["stores"].(map[string]interface{})["dynamodb"].(map[string]interface{})
+	cacheConfig := configs.Cache().Stores["dynamodb"]
 
-	awsRegion := cacheConfig["region"].(string)
-	awsAccessKey := cacheConfig["key"].(string)
-	awsSecretKey := cacheConfig["secret"].(string)
-	tableName := cacheConfig["table"].(string)
+	awsRegion := cacheConfig.Region
+	awsAccessKey := cacheConfig.Key
+	awsSecretKey := cacheConfig.Secret
+	tableName := cacheConfig.Table
 
 	if awsAccessKey == "" || awsSecretKey == "" {
 		logger.Logger.Warn("AWS credentials are not set. Falling back to default cache.")
@@ -39,8 +40,8 @@ func NewDynamoDBCache() *DynamoDBCache {
 		Credentials: credentials.NewStaticCredentials(awsAccessKey, awsSecretKey, ""),
 	}
 
-	if endpoint, ok := cacheConfig["endpoint"].(string); ok && endpoint != "" {
-		awsConfig.Endpoint = aws.String(endpoint)
+	if cacheConfig.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(cacheConfig.Endpoint)
 	}
 
 	sess, err := session.NewSession(awsConfig)
@@ -158,3 +159,35 @@ func (d *DynamoDBCache) Get(key string) (interface{}, error) {
 
 	return *result.Item["Value"].S, nil
 }
+
+// Increment atomically adds delta to the counter at key, kept in the item's
+// numeric Count attribute.
+func (d *DynamoDBCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	result, err := d.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName: aws.String(d.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"Key": {
+				S: aws.String(key),
+			},
+		},
+		UpdateExpression: aws.String("SET Expiration = if_not_exists(Expiration, :expiration) ADD #count :delta"),
+		ExpressionAttributeNames: map[string]*string{
+			"#count": aws.String("Count"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":expiration": {
+				N: aws.String(fmt.Sprintf("%d", time.Now().Add(expiration).Unix())),
+			},
+			":delta": {
+				N: aws.String(fmt.Sprintf("%d", delta)),
+			},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		logger.Logger.Errorw("Failed to increment value in DynamoDB", "key", key, "error", err)
+		return 0, err
+	}
+
+	return strconv.ParseInt(aws.StringValue(result.Attributes["Count"].N), 10, 64)
+}
@@ -3,6 +3,9 @@ package cache
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"jazz/backend/configs"
@@ -18,35 +21,40 @@ type MemcachedCache struct {
 
 // NewMemcachedCache initializes a new Memcached Cache.
 func NewMemcachedCache() *MemcachedCache {
-	cacheConfig := configs.GetCacheConfig()["stores"].(map[string]interface{})["memcached"].(map[string]interface{})
+	storeConfig := configs.Cache().Stores["memcached"]
 
-	host, okHost := cacheConfig["host"].(string)
-	port, okPort := cacheConfig["port"].(int)
+	var servers []string
+	for _, server := range storeConfig.Servers {
+		if server.Host == "" || server.Port == 0 {
+			continue
+		}
+		servers = append(servers, fmt.Sprintf("%s:%d", server.Host, server.Port))
+	}
 
-	if !okHost || host == "" || !okPort {
+	if len(servers) == 0 {
 		logger.Logger.Warn("MEMCACHED_HOST or MEMCACHED_PORT is not set or invalid. Falling back to default cache.")
 		return nil
 	}
 
-	portStr := fmt.Sprintf("%d", port)
-	client := memcache.New(fmt.Sprintf("%s:%s", host, portStr))
+	address := strings.Join(servers, ",")
+	client := memcache.New(servers...)
 
 	// Testing the connection with Memcached
 	testKey := "test_connection"
 	testValue := []byte("ping")
 	err := client.Set(&memcache.Item{Key: testKey, Value: testValue, Expiration: 1})
 	if err != nil {
-		logger.Logger.Warnf("Memcached unavailable at %s:%s. Error: %s. Falling back to default cache.", host, portStr, err)
+		logger.Logger.Warnf("Memcached unavailable at %s. Error: %s. Falling back to default cache.", address, err)
 		return nil
 	}
 
 	_, err = client.Get(testKey)
 	if err != nil {
-		logger.Logger.Warnf("Memcached unavailable at %s:%s. Error: %s. Falling back to default cache.", host, portStr, err)
+		logger.Logger.Warnf("Memcached unavailable at %s. Error: %s. Falling back to default cache.", address, err)
 		return nil
 	}
 
-	logger.Logger.Infof("Connected to Memcached at %s:%s", host, portStr)
+	logger.Logger.Infof("Connected to Memcached at %s", address)
 	return &MemcachedCache{client: client}
 }
 
@@ -120,3 +128,37 @@ func (m *MemcachedCache) Get(key string) (interface{}, error) {
 
 	return string(item.Value), nil
 }
+
+// Increment atomically adds delta to the counter at key. Memcached counters
+// are unsigned, so a decrement stops at zero.
+func (m *MemcachedCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	for {
+		var value uint64
+		var err error
+		if delta < 0 {
+			value, err = m.client.Decrement(key, uint64(-delta))
+		} else {
+			value, err = m.client.Increment(key, uint64(delta))
+		}
+		if err != memcache.ErrCacheMiss {
+			if err != nil {
+				logger.Logger.Errorw("Failed to increment value in Memcached", "key", key, "error", err)
+			}
+			return int64(value), err
+		}
+
+		// Create the counter, or adjust the one another process just created
+		initial := max(delta, 0)
+		err = m.client.Add(&memcache.Item{
+			Key:        key,
+			Value:      []byte(strconv.FormatInt(initial, 10)),
+			Expiration: int32(math.Ceil(expiration.Seconds())),
+		})
+		if err != memcache.ErrNotStored {
+			if err != nil {
+				logger.Logger.Errorw("Failed to create counter in Memcached", "key", key, "error", err)
+			}
+			return initial, err
+		}
+	}
+}
@@ -18,12 +18,8 @@ type RedisCache struct {
 
 // NewRedisCache initializes a new Redis Cache.
 func NewRedisCache() *RedisCache {
-	cacheConfig := configs.GetCacheConfig()
-	storeConfig := cacheConfig["stores"].(map[string]interface{})
-	redisConfig := storeConfig["redis"].(map[string]interface{})
-
-	redisURL, ok := redisConfig["url"].(string)
-	if !ok || redisURL == "" {
+	redisURL := configs.Cache().Stores["redis"].URL
+	if redisURL == "" {
 		logger.Logger.Warnw("REDIS_URL not set in configuration. Falling back to default cache.")
 		return nil
 	}
@@ -128,3 +124,21 @@ func (r *RedisCache) Get(key string) (interface{}, error) {
 	logger.Logger.Infow("Returning raw value from Redis cache", "key", key)
 	return val, nil
 }
+
+// incrementScript sets the expiration in the same step that creates the
+// counter, so a counter never outlives it.
+var incrementScript = redis.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value`)
+
+// Increment atomically adds delta to the counter at key.
+func (r *RedisCache) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	value, err := incrementScript.Run(context.Background(), r.client, []string{key}, delta, expiration.Milliseconds()).Int64()
+	if err != nil {
+		logger.Logger.Errorw("Failed to increment value in Redis", "key", key, "error", err)
+	}
+	return value, err
+}
@@ -2,129 +2,310 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
 	"jazz/backend/configs"
 	"jazz/backend/pkg/logger"
 	"sync"
+	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
+	"gorm.io/driver/sqlserver"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 )
 
+// maxConnectBackoff caps the wait between two connection attempts.
+const maxConnectBackoff = 5 * time.Second
+
 var (
-	dbInstance        *gorm.DB
-	once              sync.Once
-	initializationErr error
+	// connections memoizes every connection opened by name.
+	connections   = map[string]*gorm.DB{}
+	connectionsMu sync.RWMutex
+
+	// dialing holds the connections being opened, so concurrent callers
+	// share one attempt instead of each connecting on their own.
+	dialing = map[string]*dial{}
+
+	// attempted records the connections that already had their retries.
+	attempted = map[string]bool{}
+
+	// plugins are installed on every connection.
+	plugins []gorm.Plugin
 )
 
+// RegisterPlugin installs a GORM plugin on every connection, those already
+// open included, usually from the init function of the package defining it.
+func RegisterPlugin(plugin gorm.Plugin) error {
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+
+	plugins = append(plugins, plugin)
+	for name, db := range connections {
+		if err := db.Use(plugin); err != nil {
+			return fmt.Errorf("database connection %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // InitializeDatabase initializes the database using environment variables.
+// It exits when the default connection cannot be opened; use
+// DefaultConnection to handle the error instead.
 func InitializeDatabase() *gorm.DB {
-	once.Do(func() {
-		if err := configs.LoadConfig(); err != nil {
-			initializationErr = fmt.Errorf("error loading config: %v", err)
-			return
-		}
+	db, err := DefaultConnection()
+	if err != nil {
+		logger.Logger.Fatal(err.Error())
+	}
+	return db
+}
 
-		dbConfig := configs.GetDatabaseConfig()
+// GetDBInstance returns the singleton instance of the database.
+func GetDBInstance() *gorm.DB {
+	return InitializeDatabase()
+}
 
-		// Get the default connection name
-		defaultConnName, ok := dbConfig["default"].(string)
-		if !ok || defaultConnName == "" {
-			initializationErr = fmt.Errorf("no default database connection specified in configuration")
-			return
-		}
+// DefaultConnection opens the default connection, or returns the error
+// opening it. On boot it retries until the connection's connect_timeout
+// expires and returns the last error, leaving the caller to decide whether
+// to exit; see Connection.
+func DefaultConnection() (*gorm.DB, error) {
+	// Get the default connection name
+	defaultConnName := configs.Database().Default
+	if defaultConnName == "" {
+		return nil, fmt.Errorf("no default database connection specified in configuration")
+	}
 
-		// Get the connection configuration based on the default connection name
-		connections, ok := dbConfig["connections"].(map[string]interface{})
-		if !ok {
-			initializationErr = fmt.Errorf("no database connections found in configuration")
-			return
-		}
+	return Connection(defaultConnName)
+}
 
-		connectionConfig, ok := connections[defaultConnName].(map[string]interface{})
-		if !ok {
-			initializationErr = fmt.Errorf("no configuration found for the database connection: %s", defaultConnName)
-			return
-		}
+// dial is an attempt to open a connection that other callers can wait on.
+type dial struct {
+	done chan struct{}
+	db   *gorm.DB
+	err  error
+}
 
-		driver, ok := connectionConfig["driver"].(string)
-		if !ok || driver == "" {
-			initializationErr = fmt.Errorf("database driver is not specified in the connection configuration")
-			return
-		}
+// Connection returns the connection configured under name in
+// database.connections, opening it on first use. Connections are memoized,
+// so every call with the same name shares one pool.
+//
+// The first attempt to open a connection retries until its connect_timeout
+// expires. Once that failed, later calls try once and fail fast, so requests
+// do not queue behind a database that is down.
+func Connection(name string) (*gorm.DB, error) {
+	connectionsMu.RLock()
+	db, ok := connections[name]
+	connectionsMu.RUnlock()
+	if ok {
+		return db, nil
+	}
+
+	connectionConfig, ok := configs.Database().Connections[name]
+	if !ok {
+		return nil, fmt.Errorf("no configuration found for the database connection: %s", name)
+	}
+
+	connectionsMu.Lock()
+	if db, ok := connections[name]; ok {
+		connectionsMu.Unlock()
+		return db, nil
+	}
+	if pending, ok := dialing[name]; ok {
+		connectionsMu.Unlock()
+		<-pending.done
+		return pending.db, pending.err
+	}
+	pending := &dial{done: make(chan struct{})}
+	dialing[name] = pending
+	retry := !attempted[name]
+	attempted[name] = true
+	connectionsMu.Unlock()
+
+	// Connect without holding the lock, as retries may take a while
+	db, err := connect(name, connectionConfig, retry)
+	if err != nil {
+		err = fmt.Errorf("database connection %s: %w", name, err)
+	}
 
-		var err error
-		switch driver {
-		case "mysql", "mariadb":
-			dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s&parseTime=True&loc=Local",
-				connectionConfig["username"].(string),
-				connectionConfig["password"].(string),
-				connectionConfig["host"].(string),
-				connectionConfig["port"].(string),
-				connectionConfig["database"].(string),
-				connectionConfig["charset"].(string))
-			dbInstance, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-				Logger: logger.NewGormLogger(gormLogger.Info),
-			})
-		case "pgsql":
-			dsn := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s",
-				connectionConfig["host"].(string),
-				connectionConfig["port"].(string),
-				connectionConfig["username"].(string),
-				connectionConfig["database"].(string),
-				connectionConfig["password"].(string),
-				connectionConfig["sslmode"].(string))
-			dbInstance, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-				Logger: logger.NewGormLogger(gormLogger.Info),
-			})
-		case "sqlite":
-			dbPath, ok := connectionConfig["database"].(string)
-			if !ok || dbPath == "" {
-				initializationErr = fmt.Errorf("sqlite database path is not specified")
-				return
+	connectionsMu.Lock()
+	delete(dialing, name)
+	if err == nil {
+		connections[name] = db
+	}
+	connectionsMu.Unlock()
+
+	pending.db, pending.err = db, err
+	close(pending.done)
+
+	if err == nil {
+		logger.Logger.Infow("Database connection successfully established", "connection", name, "driver", connectionConfig.Driver)
+	}
+	return db, err
+}
+
+// Disconnect closes the connection opened under name, if any. The next call
+// to Connection opens it again, with retries.
+func Disconnect(name string) error {
+	connectionsMu.Lock()
+	db, ok := connections[name]
+	delete(connections, name)
+	delete(attempted, name)
+	connectionsMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// connect opens a connection. With retry, it retries with exponential backoff
+// until connect_timeout expires. Configuration errors are not retried.
+func connect(name string, connectionConfig configs.ConnectionConfig, retry bool) (*gorm.DB, error) {
+	deadline := time.Now().Add(connectionConfig.ConnectTimeout)
+	backoff := connectionConfig.ConnectBackoff
+
+	for attempt := 1; ; attempt++ {
+		db, err := open(connectionConfig)
+		if err == nil {
+			if err := db.Use(queryMonitor{connection: name}); err != nil {
+				if sqlDB, err := db.DB(); err == nil {
+					sqlDB.Close()
+				}
+				return nil, err
 			}
-			dbInstance, err = gorm.Open(sqlite.Open(dbPath), &gorm.Config{
-				Logger: logger.NewGormLogger(gormLogger.Info),
-			})
-		default:
-			initializationErr = fmt.Errorf("unsupported database driver: %s", driver)
-			return
+			return db, nil
 		}
 
-		if err != nil {
-			initializationErr = fmt.Errorf("failed to connect to database: %w", err)
-			return
+		var unavailable *unavailableError
+		if !retry || !errors.As(err, &unavailable) || backoff <= 0 || !time.Now().Add(backoff).Before(deadline) {
+			return nil, err
 		}
 
-		// Verify if the database connection is valid
-		sqlDB, err := dbInstance.DB()
-		if err != nil {
-			initializationErr = fmt.Errorf("failed to get database instance: %w", err)
-			return
-		}
+		logger.Logger.Warnw("Database is not available, retrying", "connection", name, "attempt", attempt, "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, maxConnectBackoff)
+	}
+}
 
-		if err = sqlDB.Ping(); err != nil {
-			initializationErr = fmt.Errorf("failed to ping database: %w", err)
-			return
-		}
+// unavailableError marks a failure to reach the database, which may succeed
+// on a later attempt.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string { return e.err.Error() }
 
-		logger.Logger.Info("Database connection successfully established")
+func (e *unavailableError) Unwrap() error { return e.err }
+
+// open connects to a database and verifies the connection is usable. With
+// write hosts configured, the first one is the primary connection.
+func open(connectionConfig configs.ConnectionConfig) (*gorm.DB, error) {
+	primary := connectionConfig
+	if len(connectionConfig.Write.Host) > 0 {
+		primary = withHost(connectionConfig, connectionConfig.Write, connectionConfig.Write.Host[0])
+	}
+
+	dialector, err := dialector(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.NewGormLogger(gormLogger.Info),
 	})
+	if err != nil {
+		return nil, &unavailableError{fmt.Errorf("failed to connect to database: %w", err)}
+	}
+
+	// Verify if the database connection is valid
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database instance: %w", err)
+	}
+
+	if err = sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, &unavailableError{fmt.Errorf("failed to ping database: %w", err)}
+	}
+	configurePool(sqlDB, connectionConfig)
 
-	if initializationErr != nil {
-		logger.Logger.Fatal(initializationErr.Error())
+	if err := useReadWriteSplit(db, connectionConfig); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to configure read/write connections: %w", err)
 	}
 
-	return dbInstance
+	connectionsMu.RLock()
+	defer connectionsMu.RUnlock()
+	for _, plugin := range plugins {
+		if err := db.Use(plugin); err != nil {
+			sqlDB.Close()
+			return nil, fmt.Errorf("failed to install the %s plugin: %w", plugin.Name(), err)
+		}
+	}
+
+	return db, nil
 }
 
-// GetDBInstance returns the singleton instance of the database.
-func GetDBInstance() *gorm.DB {
-	if dbInstance == nil {
-		return InitializeDatabase()
+// configurePool applies the pool settings of a connection. Zero values keep
+// the database/sql defaults.
+func configurePool(sqlDB *sql.DB, connectionConfig configs.ConnectionConfig) {
+	sqlDB.SetMaxOpenConns(connectionConfig.MaxOpen)
+	if connectionConfig.MaxIdle != 0 {
+		sqlDB.SetMaxIdleConns(connectionConfig.MaxIdle)
+	}
+	sqlDB.SetConnMaxLifetime(connectionConfig.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(connectionConfig.ConnMaxIdleTime)
+
+	// An in-memory SQLite database disappears with its last connection, and
+	// only a shared one is visible to more than one connection.
+	if memory, shared := sqliteInMemory(connectionConfig); connectionConfig.Driver == "sqlite" && memory {
+		if !shared {
+			sqlDB.SetMaxOpenConns(1)
+		}
+		sqlDB.SetMaxIdleConns(max(connectionConfig.MaxIdle, 1))
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+}
+
+// dialector returns the GORM dialector for a connection's driver.
+func dialector(connectionConfig configs.ConnectionConfig) (gorm.Dialector, error) {
+	switch connectionConfig.Driver {
+	case "mysql", "mariadb":
+		dsn, err := mysqlDSN(connectionConfig)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.Open(dsn), nil
+	case "pgsql":
+		dsn, err := postgresDSN(connectionConfig)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.Open(dsn), nil
+	case "sqlsrv":
+		dsn, err := sqlserverDSN(connectionConfig)
+		if err != nil {
+			return nil, err
+		}
+		return sqlserver.Open(dsn), nil
+	case "sqlite":
+		dsn, err := sqliteDSN(connectionConfig)
+		if err != nil {
+			return nil, err
+		}
+		return sqlite.Open(dsn), nil
+	case "":
+		return nil, fmt.Errorf("database driver is not specified in the connection configuration")
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", connectionConfig.Driver)
 	}
-	return dbInstance
 }
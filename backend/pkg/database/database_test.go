@@ -0,0 +1,550 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/logger"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestConnectionIsMemoizedPerName(t *testing.T) {
+	dir := t.TempDir()
+	for name, file := range map[string]string{"primary": "primary.sqlite", "legacy": "legacy.sqlite"} {
+		err := configs.Set("database.connections."+name, map[string]interface{}{
+			"driver":   "sqlite",
+			"database": filepath.Join(dir, file),
+		})
+		if err != nil {
+			t.Fatalf("Failed to configure connection %s: %v", name, err)
+		}
+		t.Cleanup(func() { Disconnect(name) })
+	}
+	t.Cleanup(func() { configs.ClearOverrides() })
+
+	primary, err := Connection("primary")
+	if err != nil {
+		t.Fatalf("Failed to open primary connection: %v", err)
+	}
+	again, _ := Connection("primary")
+	if primary != again {
+		t.Error("Expected the same connection to be returned for the same name")
+	}
+
+	legacy, err := Connection("legacy")
+	if err != nil {
+		t.Fatalf("Failed to open legacy connection: %v", err)
+	}
+	if legacy == primary {
+		t.Error("Expected each name to have its own connection")
+	}
+
+	primary.Exec("CREATE TABLE things (id INTEGER)")
+	if legacy.Migrator().HasTable("things") {
+		t.Error("Expected connections to point at their own databases")
+	}
+
+	if _, err := Connection("missing"); err == nil {
+		t.Error("Expected an error for an unknown connection")
+	}
+}
+
+func TestStickyWritesReadFromPrimary(t *testing.T) {
+	dir := t.TempDir()
+	primaryPath, replicaPath := filepath.Join(dir, "primary.sqlite"), filepath.Join(dir, "replica.sqlite")
+	for _, path := range []string{primaryPath, replicaPath} {
+		db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		db.Exec("CREATE TABLE things (id INTEGER)")
+	}
+
+	db, err := gorm.Open(sqlite.Open(primaryPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open primary: %v", err)
+	}
+	if _, err := splitReadWrite(db, nil, []gorm.Dialector{sqlite.Open(replicaPath)}, true); err != nil {
+		t.Fatalf("Failed to split reads and writes: %v", err)
+	}
+
+	count := func(db *gorm.DB) int64 {
+		var n int64
+		db.Table("things").Count(&n)
+		return n
+	}
+
+	ctx := WithStickyWrites(context.Background())
+	if count(db.WithContext(ctx)) != 0 {
+		t.Fatal("Expected an empty table before writing")
+	}
+	if err := db.WithContext(ctx).Exec("INSERT INTO things (id) VALUES (1)").Error; err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	if n := count(db); n != 0 {
+		t.Errorf("Expected reads outside the request to use the replica, got %d rows", n)
+	}
+	if n := count(db.WithContext(ctx)); n != 1 {
+		t.Errorf("Expected the request that wrote to read from the primary, got %d rows", n)
+	}
+}
+
+func TestDSNBuilders(t *testing.T) {
+	tests := []struct {
+		name   string
+		build  func(configs.ConnectionConfig) (string, error)
+		config configs.ConnectionConfig
+		want   string
+	}{
+		{
+			name:   "mysql over tcp",
+			build:  mysqlDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 3306, Database: "jazz", Username: "root", Password: "secret", Charset: "utf8mb4"},
+			want:   "root:secret@tcp(db:3306)/jazz?clientFoundRows=true&loc=Local&parseTime=true&charset=utf8mb4",
+		},
+		{
+			name:   "mysql over unix socket",
+			build:  mysqlDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 3306, Database: "jazz", Username: "root", UnixSocket: "/run/mysqld/mysqld.sock"},
+			want:   "root@unix(/run/mysqld/mysqld.sock)/jazz?clientFoundRows=true&loc=Local&parseTime=true",
+		},
+		{
+			name:   "mysql url",
+			build:  mysqlDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 3306, Database: "jazz", URL: "mysql://app:pw@replica/shop?charset=latin1"},
+			want:   "app:pw@tcp(replica:3306)/shop?clientFoundRows=true&loc=Local&parseTime=true&charset=latin1",
+		},
+		{
+			name:   "pgsql with search path and ca",
+			build:  postgresDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 5432, Database: "jazz", Username: "root", Password: "it's", SearchPath: "app,public", SSLMode: "verify-full", Options: map[string]string{"ssl_ca": "/certs/ca.pem"}},
+			want:   `dbname=jazz host=db password='it\'s' port=5432 search_path=app,public sslmode=verify-full sslrootcert=/certs/ca.pem user=root`,
+		},
+		{
+			name:   "pgsql url",
+			build:  postgresDSN,
+			config: configs.ConnectionConfig{Host: "db", URL: "pgsql://root:pw@db:5432/jazz?sslmode=disable"},
+			want:   "postgres://root:pw@db:5432/jazz?sslmode=disable",
+		},
+		{
+			name:   "sqlsrv",
+			build:  sqlserverDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 1433, Database: "jazz", Username: "sa", Password: "p@ss", Encrypt: "true", TrustServerCertificate: true},
+			want:   "sqlserver://sa:p%40ss@db:1433?TrustServerCertificate=true&database=jazz&encrypt=true",
+		},
+		{
+			name:   "sqlsrv url",
+			build:  sqlserverDSN,
+			config: configs.ConnectionConfig{Host: "db", Port: 1433, Database: "jazz", URL: "sqlsrv://sa:pw@other:1444/shop"},
+			want:   "sqlserver://sa:pw@other:1444?database=shop",
+		},
+		{
+			name:   "sqlite url",
+			build:  sqliteDSN,
+			config: configs.ConnectionConfig{Database: "jazz.sqlite", URL: "sqlite:///var/data/app.sqlite"},
+			want:   "/var/data/app.sqlite?_foreign_keys=false",
+		},
+		{
+			name:   "sqlite pragmas",
+			build:  sqliteDSN,
+			config: configs.ConnectionConfig{Database: "/var/data/app.sqlite?_synchronous=FULL", ForeignKeyConstraints: true, BusyTimeout: 3000, JournalMode: "WAL", Synchronous: "NORMAL"},
+			want:   "/var/data/app.sqlite?_busy_timeout=3000&_foreign_keys=true&_journal_mode=WAL&_synchronous=FULL",
+		},
+		{
+			name:   "sqlite relative path",
+			build:  sqliteDSN,
+			config: configs.ConnectionConfig{Database: "database/database.sqlite"},
+			want:   configs.BasePath("database", "database.sqlite") + "?_foreign_keys=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build(tt.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected DSN %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConnectRetriesUntilTheDatabaseIsAvailable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "later")
+	connectionConfig := configs.ConnectionConfig{
+		Driver:         "sqlite",
+		Database:       filepath.Join(dir, "jazz.sqlite"),
+		MaxOpen:        3,
+		ConnectTimeout: 5 * time.Second,
+		ConnectBackoff: 20 * time.Millisecond,
+	}
+
+	// The directory, and so the database, appears after a few attempts
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.Mkdir(dir, 0o755)
+	}()
+
+	db, err := connect("later", connectionConfig, true)
+	if err != nil {
+		t.Fatalf("Expected the connection to succeed once the database is available, got %v", err)
+	}
+	sqlDB, _ := db.DB()
+	defer sqlDB.Close()
+	if got := sqlDB.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("Expected max_open to be applied, got %d", got)
+	}
+}
+
+func TestConnectGivesUpAfterTheTimeout(t *testing.T) {
+	connectionConfig := configs.ConnectionConfig{
+		Driver:         "sqlite",
+		Database:       filepath.Join(t.TempDir(), "missing", "jazz.sqlite"),
+		ConnectTimeout: 150 * time.Millisecond,
+		ConnectBackoff: 20 * time.Millisecond,
+	}
+
+	start := time.Now()
+	if _, err := connect("missing", connectionConfig, true); err == nil {
+		t.Fatal("Expected an error for a database that never becomes available")
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Expected retries to stop around the timeout, took %s", elapsed)
+	}
+}
+
+func TestConnectionFailsFastAfterTheFirstAttempt(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "later")
+	err := configs.Set("database.connections.flaky", map[string]interface{}{
+		"driver":          "sqlite",
+		"database":        filepath.Join(dir, "jazz.sqlite"),
+		"connect_timeout": "150ms",
+		"connect_backoff": "20ms",
+	})
+	if err != nil {
+		t.Fatalf("Failed to configure connection: %v", err)
+	}
+	t.Cleanup(func() {
+		Disconnect("flaky")
+		configs.ClearOverrides()
+	})
+
+	start := time.Now()
+	if _, err := Connection("flaky"); err == nil {
+		t.Fatal("Expected an error for a database that is not available")
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected the first attempt to retry, took %s", elapsed)
+	}
+
+	start = time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if db, err := Connection("flaky"); err == nil || db != nil {
+				t.Errorf("Expected an error and no connection, got %v (%v)", db, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+		t.Errorf("Expected later attempts to fail fast, took %s", elapsed)
+	}
+
+	// A later call connects once the database is back
+	os.Mkdir(dir, 0o755)
+	if _, err := Connection("flaky"); err != nil {
+		t.Errorf("Expected the connection to recover, got %v", err)
+	}
+}
+
+func TestInMemorySQLiteKeepsItsData(t *testing.T) {
+	for name, database := range map[string]string{
+		"private": ":memory:",
+		"shared":  "file:jazz_test?mode=memory&cache=shared",
+	} {
+		t.Run(name, func(t *testing.T) {
+			db, err := open(configs.ConnectionConfig{Driver: "sqlite", Database: database, ForeignKeyConstraints: true, MaxOpen: 4})
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", database, err)
+			}
+			sqlDB, _ := db.DB()
+			defer sqlDB.Close()
+
+			db.Exec("CREATE TABLE parents (id INTEGER PRIMARY KEY)")
+			db.Exec("CREATE TABLE children (parent_id INTEGER REFERENCES parents(id))")
+			if err := db.Exec("INSERT INTO children VALUES (1)").Error; err == nil {
+				t.Error("Expected foreign keys to be enforced")
+			}
+
+			// Every pooled connection must see the same database
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					var n int64
+					if err := db.Table("parents").Count(&n).Error; err != nil {
+						t.Errorf("Expected the table to exist on every connection: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+type note struct {
+	ID        uint
+	Title     string
+	Body      string
+	DeletedAt gorm.DeletedAt
+}
+
+func TestRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repository.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	notes := NewRepository[note](db)
+	ctx := context.Background()
+
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		if err := notes.Create(ctx, &note{Title: title}); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+	}
+
+	if n, err := notes.Find(ctx, 2); err != nil || n.Title != "b" {
+		t.Errorf("Expected note b, got %+v, %v", n, err)
+	}
+	if _, err := notes.FindBy(ctx, "title", "z"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if found, err := notes.Where(ctx, "title > ?", "c"); err != nil || len(found) != 2 {
+		t.Errorf("Expected 2 notes after c, got %d, %v", len(found), err)
+	}
+
+	n, _ := notes.FindBy(ctx, "title", "a")
+	if err := notes.Update(ctx, n, map[string]interface{}{"body": "updated"}); err != nil || n.Body != "updated" {
+		t.Errorf("Expected the note to be updated, got %+v, %v", n, err)
+	}
+	if err := notes.Update(ctx, n, map[string]interface{}{"body": "updated"}); err != nil {
+		t.Errorf("Expected an update that changes nothing to succeed, got %v", err)
+	}
+	if err := notes.Delete(ctx, n); err != nil {
+		t.Errorf("Failed to delete note: %v", err)
+	}
+	if err := notes.Delete(ctx, n); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected deleting twice to return ErrNotFound, got %v", err)
+	}
+	if exists, err := notes.Exists(ctx, "title = ?", "a"); err != nil || exists {
+		t.Errorf("Expected the deleted note to be gone, got %v, %v", exists, err)
+	}
+
+	if _, created, err := notes.FirstOrCreate(ctx, note{Title: "b"}); err != nil || created {
+		t.Errorf("Expected note b to be found, got created=%v, %v", created, err)
+	}
+	f, created, err := notes.FirstOrCreate(ctx, note{Title: "f"}, note{Body: "new"})
+	if err != nil || !created || f.Body != "new" {
+		t.Errorf("Expected note f to be created, got %+v, created=%v, %v", f, created, err)
+	}
+
+	page, err := notes.Paginate(ctx, 2, 2)
+	if err != nil || page.Total != 5 || len(page.Items) != 2 || page.Items[0].Title != "d" {
+		t.Errorf("Expected the second page of 5 notes to start at d, got %+v, %v", page, err)
+	}
+
+	var sizes []int
+	err = notes.Chunk(ctx, 2, func(batch []note) error {
+		sizes = append(sizes, len(batch))
+		return nil
+	})
+	if err != nil || len(sizes) != 3 || sizes[2] != 1 {
+		t.Errorf("Expected batches of 2, 2 and 1, got %v, %v", sizes, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := notes.Find(cancelled, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled context to abort the query, got %v", err)
+	}
+}
+
+func TestTransactionJoinsNestedCallsAndRetriesDeadlocks(t *testing.T) {
+	logger.InitializeLogger()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "transaction.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	notes := NewRepository[note](db)
+	policy := configs.TransactionsConfig{Attempts: 3, Backoff: time.Millisecond}
+	titles := func() []string {
+		var titles []string
+		db.Model(&note{}).Order("id").Pluck("title", &titles)
+		return titles
+	}
+
+	// A failed savepoint only rolls back its own changes
+	var committed []string
+	err = transaction(context.Background(), db, policy, func(ctx context.Context) error {
+		if err := notes.Create(ctx, &note{Title: "outer"}); err != nil {
+			return err
+		}
+		AfterCommit(ctx, func() { committed = append(committed, "outer") })
+		nested := transaction(ctx, db, policy, func(ctx context.Context) error {
+			notes.Create(ctx, &note{Title: "nested"})
+			AfterCommit(ctx, func() { committed = append(committed, "nested") })
+			return errors.New("nested failure")
+		})
+		if nested == nil {
+			t.Error("Expected the nested error to be returned")
+		}
+		if len(committed) != 0 {
+			t.Error("Expected AfterCommit to wait for the commit")
+		}
+		return nil
+	})
+	if got := titles(); err != nil || len(got) != 1 || got[0] != "outer" {
+		t.Errorf("Expected only the outer note to be committed, got %v, %v", got, err)
+	}
+	if len(committed) != 1 || committed[0] != "outer" {
+		t.Errorf("Expected only the outer AfterCommit to run, got %v", committed)
+	}
+
+	// Repositories on another connection do not join the transaction
+	other, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "other.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	other.AutoMigrate(&note{})
+	otherNotes := NewRepository[note](other)
+	transaction(context.Background(), db, policy, func(ctx context.Context) error {
+		if err := otherNotes.Create(ctx, &note{Title: "elsewhere"}); err != nil {
+			t.Errorf("Failed to create note on the other connection: %v", err)
+		}
+		return errors.New("roll back")
+	})
+	if exists, _ := otherNotes.Exists(context.Background(), "title = ?", "elsewhere"); !exists {
+		t.Error("Expected the other connection's write to survive the rollback")
+	}
+
+	// Deadlocks are retried and each failed attempt is rolled back
+	attempts := 0
+	committed = nil
+	err = transaction(context.Background(), db, policy, func(ctx context.Context) error {
+		attempts++
+		notes.Create(ctx, &note{Title: "retried"})
+		AfterCommit(ctx, func() { committed = append(committed, "retried") })
+		if attempts < 3 {
+			return &mysqlDriver.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+		}
+		return nil
+	})
+	if got := titles(); err != nil || attempts != 3 || len(got) != 2 {
+		t.Errorf("Expected 3 attempts and one retried note, got %d, %v, %v", attempts, got, err)
+	}
+	if len(committed) != 1 {
+		t.Errorf("Expected AfterCommit to run for the committed attempt only, got %v", committed)
+	}
+
+	// Other errors and exhausted attempts are returned as is
+	attempts = 0
+	serialization := &pgconn.PgError{Code: "40001"}
+	err = transaction(context.Background(), db, policy, func(ctx context.Context) error {
+		attempts++
+		return serialization
+	})
+	if !errors.Is(err, serialization) || attempts != 3 {
+		t.Errorf("Expected 3 attempts ending in the serialization failure, got %d, %v", attempts, err)
+	}
+	attempts = 0
+	err = transaction(context.Background(), db, policy, func(ctx context.Context) error {
+		attempts++
+		return ErrNotFound
+	})
+	if !errors.Is(err, ErrNotFound) || attempts != 1 {
+		t.Errorf("Expected a single attempt for a non retryable error, got %d, %v", attempts, err)
+	}
+}
+
+func TestQueryMonitorCountsAndLogsQueries(t *testing.T) {
+	logger.InitializeLogger()
+	if err := configs.Set("database.queries.slow_threshold", "1ns"); err != nil {
+		t.Fatalf("Failed to lower the slow query threshold: %v", err)
+	}
+	t.Cleanup(func() { configs.ClearOverrides() })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "monitor.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.Use(queryMonitor{connection: "monitored"}); err != nil {
+		t.Fatalf("Failed to install the monitor: %v", err)
+	}
+	db.AutoMigrate(&note{})
+
+	ctx, counter := WithQueryCounter(context.Background())
+	notes := NewRepository[note](db)
+	for i := 1; i <= 3; i++ {
+		notes.Create(ctx, &note{Title: "bound value"})
+		notes.Find(ctx, i)
+	}
+	notes.Where(ctx, "id IN ?", []int{1, 2})
+	notes.Where(ctx, "id IN ?", []int{1, 2, 3})
+	notes.Query(ctx).Count(new(int64))
+
+	if count, elapsed := counter.Count(); count != 9 || elapsed <= 0 {
+		t.Errorf("Expected 9 queries to be counted, got %d in %s", count, elapsed)
+	}
+	repeated := counter.Repeated(2)
+	if len(repeated) != 2 {
+		t.Errorf("Expected the inserts and lookups to repeat 3 times, got %v", repeated)
+	}
+	if n := repeated["SELECT * FROM `notes` WHERE `notes`.`id` = ? AND `notes`.`deleted_at` IS NULL ORDER BY `notes`.`id` LIMIT 1"]; n != 3 {
+		t.Errorf("Expected the lookup by id to repeat 3 times, got %d in %v", n, repeated)
+	}
+	if len(counter.Repeated(1)) != 3 {
+		t.Errorf("Expected IN lists of any length to have the same shape, got %v", counter.Repeated(1))
+	}
+
+	var found bool
+	for _, m := range QueryMetrics() {
+		if m.Connection == "monitored" && m.Table == "notes" {
+			found = m.Count >= 9 && m.Total > 0 && m.Max > 0
+		}
+	}
+	if !found {
+		t.Errorf("Expected metrics for the notes table, got %+v", QueryMetrics())
+	}
+
+	slowLog, err := os.ReadFile(configs.BasePath("storage", "logs", "slow_queries.log"))
+	if err != nil || !strings.Contains(string(slowLog), "monitored") {
+		t.Errorf("Expected slow queries to be logged to their channel: %v", err)
+	}
+	if strings.Contains(string(slowLog), "bound value") {
+		t.Error("Expected slow queries to be logged without their bound values")
+	}
+}
@@ -0,0 +1,214 @@
+// database/metrics.go
+package database
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// QueryMetric sums up the queries run on one table of a connection.
+type QueryMetric struct {
+	Connection string        `json:"connection"`
+	Table      string        `json:"table"`
+	Count      int64         `json:"count"`
+	Errors     int64         `json:"errors"`
+	Total      time.Duration `json:"total_ns"`
+	Max        time.Duration `json:"max_ns"`
+}
+
+type metricKey struct{ connection, table string }
+
+var (
+	metrics   = map[metricKey]*QueryMetric{}
+	metricsMu sync.Mutex
+)
+
+func init() {
+	// Served with the other expvars at /debug/vars by expvar.Handler
+	expvar.Publish("database.queries", expvar.Func(func() interface{} { return QueryMetrics() }))
+}
+
+// QueryMetrics returns the query counts and durations per connection and
+// table since the process started, sorted by connection and table.
+func QueryMetrics() []QueryMetric {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	result := make([]QueryMetric, 0, len(metrics))
+	for _, m := range metrics {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Connection != result[j].Connection {
+			return result[i].Connection < result[j].Connection
+		}
+		return result[i].Table < result[j].Table
+	})
+	return result
+}
+
+func record(connection, table string, elapsed time.Duration, failed bool) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	key := metricKey{connection, table}
+	m, ok := metrics[key]
+	if !ok {
+		m = &QueryMetric{Connection: connection, Table: table}
+		metrics[key] = m
+	}
+	m.Count++
+	m.Total += elapsed
+	m.Max = max(m.Max, elapsed)
+	if failed {
+		m.Errors++
+	}
+}
+
+// QueryCounter counts the queries run with a context, usually a request's.
+type QueryCounter struct {
+	mu      sync.Mutex
+	count   int
+	elapsed time.Duration
+	shapes  map[string]int
+}
+
+type queryCounterKey struct{}
+
+// WithQueryCounter returns a context counting the queries run with it.
+func WithQueryCounter(ctx context.Context) (context.Context, *QueryCounter) {
+	counter := &QueryCounter{shapes: map[string]int{}}
+	return context.WithValue(ctx, queryCounterKey{}, counter), counter
+}
+
+// QueryCounterFrom returns the counter attached to ctx, or nil.
+func QueryCounterFrom(ctx context.Context) *QueryCounter {
+	counter, _ := ctx.Value(queryCounterKey{}).(*QueryCounter)
+	return counter
+}
+
+// Count returns the number of queries run and their total duration.
+func (c *QueryCounter) Count() (int, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count, c.elapsed
+}
+
+// Repeated returns the queries, with placeholders for their values, run
+// more than threshold times, and how many times each ran. Such repeats are
+// usually an N+1 problem: a query per record instead of one for them all.
+func (c *QueryCounter) Repeated(threshold int) map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	repeated := map[string]int{}
+	for shape, n := range c.shapes {
+		if n > threshold {
+			repeated[shape] = n
+		}
+	}
+	return repeated
+}
+
+func (c *QueryCounter) add(shape string, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	c.elapsed += elapsed
+	c.shapes[shape]++
+}
+
+// placeholderLists matches IN lists, which differ only by their length.
+var placeholderLists = regexp.MustCompile(`\(\s*\?(\s*,\s*\?)*\s*\)`)
+
+// queryShape returns the SQL of a statement with one placeholder per list.
+func queryShape(sql string) string {
+	return placeholderLists.ReplaceAllString(strings.TrimSpace(sql), "(?)")
+}
+
+// queryMonitor times every statement of a connection to record metrics,
+// count the queries of the statement's context and log slow queries.
+type queryMonitor struct {
+	connection string
+}
+
+const startedAtKey = "database:started_at"
+
+func (m queryMonitor) Name() string {
+	return "database:query_monitor"
+}
+
+func (m queryMonitor) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register("database:start_create", m.start),
+		callbacks.Create().After("*").Register("database:finish_create", m.finish),
+		callbacks.Query().Before("*").Register("database:start_query", m.start),
+		callbacks.Query().After("*").Register("database:finish_query", m.finish),
+		callbacks.Update().Before("*").Register("database:start_update", m.start),
+		callbacks.Update().After("*").Register("database:finish_update", m.finish),
+		callbacks.Delete().Before("*").Register("database:start_delete", m.start),
+		callbacks.Delete().After("*").Register("database:finish_delete", m.finish),
+		callbacks.Row().Before("*").Register("database:start_row", m.start),
+		callbacks.Row().After("*").Register("database:finish_row", m.finish),
+		callbacks.Raw().Before("*").Register("database:start_raw", m.start),
+		callbacks.Raw().After("*").Register("database:finish_raw", m.finish),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m queryMonitor) start(db *gorm.DB) {
+	db.Statement.Settings.Store(startedAtKey, time.Now())
+}
+
+func (m queryMonitor) finish(db *gorm.DB) {
+	started, ok := db.Statement.Settings.Load(startedAtKey)
+	if !ok || db.Statement.SQL.Len() == 0 {
+		return
+	}
+	elapsed := time.Since(started.(time.Time))
+	sql := db.Statement.SQL.String()
+
+	table := db.Statement.Table
+	if table == "" {
+		table = "(raw)"
+	}
+	record(m.connection, table, elapsed, db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound))
+
+	if counter := QueryCounterFrom(db.Statement.Context); counter != nil {
+		counter.add(queryShape(sql), elapsed)
+	}
+
+	queries := configs.Database().Queries
+	if threshold := queries.SlowThreshold; threshold > 0 && elapsed > threshold {
+		// Bound values may hold secrets such as password hashes, so they
+		// are only written when asked for
+		logged := sql
+		if queries.SlowBindings {
+			logged = db.Dialector.Explain(sql, db.Statement.Vars...)
+		}
+		logger.Channel(queries.SlowChannel).Warnw("Slow query",
+			"connection", m.connection,
+			"table", table,
+			"elapsed", elapsed,
+			"threshold", threshold,
+			"rows", db.Statement.RowsAffected,
+			"sql", logged,
+		)
+	}
+}
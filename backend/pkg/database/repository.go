@@ -0,0 +1,154 @@
+// database/repository.go
+package database
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound is returned when a lookup matches no record.
+var ErrNotFound = errors.New("record not found")
+
+// Repository runs typed queries for the model T. Every method runs with the
+// given context, so cancellation and sticky writes follow the request, and
+// joins the transaction the context carries, if any.
+type Repository[T any] struct {
+	db *gorm.DB
+}
+
+// Page is one page of results and the total number of matching records.
+type Page[T any] struct {
+	Items   []T
+	Total   int64
+	Page    int
+	PerPage int
+}
+
+// NewRepository returns a Repository for T on db.
+func NewRepository[T any](db *gorm.DB) *Repository[T] {
+	return &Repository[T]{db: db}
+}
+
+// Query returns a query on T's table for anything the methods do not cover.
+func (r *Repository[T]) Query(ctx context.Context) *gorm.DB {
+	return FromContext(ctx, r.db).Model(new(T))
+}
+
+// Find returns the record with the primary key id.
+func (r *Repository[T]) Find(ctx context.Context, id interface{}) (*T, error) {
+	var model T
+	if err := FromContext(ctx, r.db).First(&model, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &model, nil
+}
+
+// FindBy returns the first record whose column equals value.
+func (r *Repository[T]) FindBy(ctx context.Context, column string, value interface{}) (*T, error) {
+	var model T
+	err := FromContext(ctx, r.db).Where(clause.Eq{Column: clause.Column{Name: column}, Value: value}).First(&model).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &model, nil
+}
+
+// Where returns every record matching the conditions, given as in gorm's Where.
+func (r *Repository[T]) Where(ctx context.Context, query interface{}, args ...interface{}) ([]T, error) {
+	var models []T
+	if err := FromContext(ctx, r.db).Where(query, args...).Find(&models).Error; err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Exists reports whether a record matches the conditions.
+func (r *Repository[T]) Exists(ctx context.Context, query interface{}, args ...interface{}) (bool, error) {
+	var found []T
+	err := FromContext(ctx, r.db).Where(query, args...).Limit(1).Find(&found).Error
+	return len(found) > 0, err
+}
+
+// Create inserts a record and fills in its primary key.
+func (r *Repository[T]) Create(ctx context.Context, model *T) error {
+	return FromContext(ctx, r.db).Create(model).Error
+}
+
+// Update sets values, a map or a struct whose zero fields are skipped, on a
+// saved record. It returns ErrNotFound when no record matches; an update that
+// changes nothing still succeeds.
+func (r *Repository[T]) Update(ctx context.Context, model *T, values interface{}) error {
+	result := FromContext(ctx, r.db).Model(model).Updates(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete deletes a saved record, softly when T has a gorm.DeletedAt field.
+func (r *Repository[T]) Delete(ctx context.Context, model *T) error {
+	result := FromContext(ctx, r.db).Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FirstOrCreate returns the first record matching conditions, or creates one
+// from conditions and values. created tells which happened.
+func (r *Repository[T]) FirstOrCreate(ctx context.Context, conditions T, values ...T) (model *T, created bool, err error) {
+	model = new(T)
+	query := FromContext(ctx, r.db).Where(conditions)
+	for _, v := range values {
+		query = query.Attrs(v)
+	}
+	result := query.FirstOrCreate(model)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+	// FirstOrCreate only reports affected rows when it inserts
+	return model, result.RowsAffected > 0, nil
+}
+
+// Paginate returns the page-th page, counted from 1, of perPage records
+// matching the scopes.
+func (r *Repository[T]) Paginate(ctx context.Context, page, perPage int, scopes ...func(*gorm.DB) *gorm.DB) (*Page[T], error) {
+	page, perPage = max(page, 1), max(perPage, 1)
+	query := r.Query(ctx).Scopes(scopes...)
+
+	result := &Page[T]{Page: page, PerPage: perPage}
+	if err := query.Session(&gorm.Session{}).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Offset((page - 1) * perPage).Limit(perPage).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Chunk calls fn with the records matching the scopes, size at a time in
+// primary key order, so large tables never sit in memory at once. An error
+// from fn stops the iteration.
+func (r *Repository[T]) Chunk(ctx context.Context, size int, fn func(batch []T) error, scopes ...func(*gorm.DB) *gorm.DB) error {
+	var batch []T
+	return FromContext(ctx, r.db).Scopes(scopes...).FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// notFound turns gorm's not found error into ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
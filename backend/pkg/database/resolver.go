@@ -0,0 +1,137 @@
+// database/resolver.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+
+	"jazz/backend/configs"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// stickyKey is the context key holding the sticky write state of a request.
+type stickyKey struct{}
+
+// stickyState records whether a request has written to a primary.
+type stickyState struct {
+	wrote atomic.Bool
+}
+
+// WithStickyWrites returns a context in which, once a write succeeds, reads
+// on connections with sticky enabled go to the primary. Queries must run
+// with db.WithContext(ctx) to share the state.
+func WithStickyWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stickyKey{}, &stickyState{})
+}
+
+// useReadWriteSplit routes the queries of db to replicas and the other
+// statements to the primaries, when the connection defines read or write hosts.
+func useReadWriteSplit(db *gorm.DB, connectionConfig configs.ConnectionConfig) error {
+	if len(connectionConfig.Read.Host) == 0 && len(connectionConfig.Write.Host) <= 1 {
+		return nil
+	}
+
+	var sources, replicas []gorm.Dialector
+	if len(connectionConfig.Write.Host) > 1 {
+		for _, host := range connectionConfig.Write.Host {
+			d, err := dialector(withHost(connectionConfig, connectionConfig.Write, host))
+			if err != nil {
+				return err
+			}
+			sources = append(sources, d)
+		}
+	}
+	for _, host := range connectionConfig.Read.Host {
+		d, err := dialector(withHost(connectionConfig, connectionConfig.Read, host))
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, d)
+	}
+
+	resolver, err := splitReadWrite(db, sources, replicas, connectionConfig.Sticky)
+	if err != nil {
+		return err
+	}
+
+	// Replicas and additional primaries get the same pool settings
+	return resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			configurePool(sqlDB, connectionConfig)
+		}
+		return nil
+	})
+}
+
+// splitReadWrite registers the resolver, and the sticky callbacks when asked to.
+func splitReadWrite(db *gorm.DB, sources, replicas []gorm.Dialector, sticky bool) (*dbresolver.DBResolver, error) {
+	resolver := dbresolver.Register(dbresolver.Config{
+		Sources:  sources,
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	})
+	if err := db.Use(resolver); err != nil || !sticky {
+		return resolver, err
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Query().After("gorm:db_resolver").Before("gorm:query").Register("jazz:sticky_read", stickyRead),
+		callbacks.Row().After("gorm:db_resolver").Before("gorm:row").Register("jazz:sticky_read", stickyRead),
+		callbacks.Raw().After("gorm:db_resolver").Before("gorm:raw").Register("jazz:sticky_read", stickyRead),
+		callbacks.Create().After("*").Register("jazz:sticky_write", stickyWrite),
+		callbacks.Update().After("*").Register("jazz:sticky_write", stickyWrite),
+		callbacks.Delete().After("*").Register("jazz:sticky_write", stickyWrite),
+		callbacks.Raw().After("*").Register("jazz:sticky_write", stickyWrite),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolver, nil
+}
+
+// withHost returns the connection settings for one host of a read/write split.
+func withHost(connectionConfig configs.ConnectionConfig, hosts configs.HostsConfig, host string) configs.ConnectionConfig {
+	connectionConfig.Host = host
+	// A URL would take precedence over the host
+	connectionConfig.URL = ""
+	if hosts.Port != 0 {
+		connectionConfig.Port = hosts.Port
+	}
+	if hosts.Username != "" {
+		connectionConfig.Username = hosts.Username
+	}
+	if hosts.Password != "" {
+		connectionConfig.Password = hosts.Password
+	}
+	return connectionConfig
+}
+
+// stickyRead sends reads to the primary once the request has written. Marking
+// the statement as a write makes the resolver pick a primary again.
+func stickyRead(db *gorm.DB) {
+	if state, ok := db.Statement.Context.Value(stickyKey{}).(*stickyState); ok && state.wrote.Load() {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// stickyWrite records a successful write for the rest of the request.
+func stickyWrite(db *gorm.DB) {
+	if db.Error != nil || isRead(db.Statement.SQL.String()) {
+		return
+	}
+	if state, ok := db.Statement.Context.Value(stickyKey{}).(*stickyState); ok {
+		state.wrote.Store(true)
+	}
+}
+
+// isRead reports whether a raw statement only reads data.
+func isRead(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	return len(sql) >= 6 && strings.EqualFold(sql[:6], "select")
+}
@@ -0,0 +1,164 @@
+// database/transaction.go
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/logger"
+
+	mysqlDriver "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key of the transaction running on the connection
+// whose pool is pool, so that a context can carry one per connection.
+type txKey struct {
+	pool gorm.ConnPool
+}
+
+// currentTxKey is the context key of the innermost transaction.
+type currentTxKey struct{}
+
+// txState is the transaction a context carries and the functions waiting
+// for it to commit.
+type txState struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+// keyFor returns the transaction key of db's connection. Every session of a
+// connection shares its pool.
+func keyFor(db *gorm.DB) txKey {
+	return txKey{pool: db.Config.ConnPool}
+}
+
+// Transaction runs fn in a transaction on the default connection. See
+// TransactionOn.
+func Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return TransactionOn(ctx, configs.Database().Default, fn)
+}
+
+// TransactionOn runs fn in a transaction on the named connection and commits
+// it when fn returns nil. The context given to fn carries the transaction,
+// so repositories and FromContext called with it on that connection join
+// the transaction. Queries on other connections run outside of it.
+//
+// Called with a context already carrying a transaction on the connection,
+// TransactionOn runs fn within a savepoint of it instead: an error rolls
+// back fn's changes only.
+//
+// A transaction failing on a deadlock or a serialization failure is run
+// again, as configured by database.transactions, so fn must not have side
+// effects outside the database. Use AfterCommit for those.
+func TransactionOn(ctx context.Context, connection string, fn func(ctx context.Context) error) error {
+	db, err := Connection(connection)
+	if err != nil {
+		return err
+	}
+	return transaction(ctx, db, configs.Database().Transactions, fn)
+}
+
+// FromContext returns the transaction ctx carries on db's connection, or db
+// bound to ctx when there is none.
+func FromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(keyFor(db)).(*txState); ok {
+		return state.db.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// AfterCommit runs fn once the innermost transaction carried by ctx commits,
+// or right away when ctx carries none. fn never runs when the transaction,
+// or the savepoint fn was registered in, rolls back.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(currentTxKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+// DeferAfterCommit returns a context in which AfterCommit queues its
+// functions, and the function running them, for code committing a
+// transaction of its own such as GORM's default one. When ctx already
+// carries a transaction, ctx is returned with a function doing nothing.
+func DeferAfterCommit(ctx context.Context) (context.Context, func()) {
+	if _, ok := ctx.Value(currentTxKey{}).(*txState); ok {
+		return ctx, func() {}
+	}
+	state := &txState{}
+	return context.WithValue(ctx, currentTxKey{}, state), func() {
+		for _, fn := range state.afterCommit {
+			fn()
+		}
+	}
+}
+
+// withTx returns a context carrying state as the transaction of key's
+// connection and as the innermost one.
+func withTx(ctx context.Context, key txKey, state *txState) context.Context {
+	return context.WithValue(context.WithValue(ctx, key, state), currentTxKey{}, state)
+}
+
+// transaction runs fn in a transaction on db, or in a savepoint of the one
+// ctx carries on db's connection, retrying it as policy allows.
+func transaction(ctx context.Context, db *gorm.DB, policy configs.TransactionsConfig, fn func(ctx context.Context) error) error {
+	key := keyFor(db)
+	if parent, ok := ctx.Value(key).(*txState); ok {
+		state := &txState{}
+		err := parent.db.WithContext(ctx).Transaction(func(nested *gorm.DB) error {
+			state.db = nested
+			return fn(withTx(ctx, key, state))
+		})
+		if err == nil {
+			parent.afterCommit = append(parent.afterCommit, state.afterCommit...)
+		}
+		return err
+	}
+
+	attempts := max(policy.Attempts, 1)
+	for attempt := 1; ; attempt++ {
+		state := &txState{}
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			state.db = tx
+			return fn(withTx(ctx, key, state))
+		})
+		if err == nil {
+			for _, fn := range state.afterCommit {
+				fn()
+			}
+			return nil
+		}
+		if attempt >= attempts || !retryable(err) {
+			return err
+		}
+
+		backoff := time.Duration(attempt) * policy.Backoff
+		logger.Logger.Warnw("Transaction failed, retrying", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// retryable reports whether err is a deadlock or serialization failure,
+// after which running the transaction again may succeed.
+func retryable(err error) bool {
+	var mysqlErr *mysqlDriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_LOCK_DEADLOCK and ER_LOCK_WAIT_TIMEOUT
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// serialization_failure and deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return false
+}
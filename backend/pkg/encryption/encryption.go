@@ -0,0 +1,378 @@
+// Package encryption encrypts and decrypts values with the application key,
+// similar to Laravel's Crypt facade.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"jazz/backend/configs"
+)
+
+// Supported ciphers.
+const (
+	AES128CBC = "AES-128-CBC"
+	AES256CBC = "AES-256-CBC"
+	AES128GCM = "AES-128-GCM"
+	AES256GCM = "AES-256-GCM"
+)
+
+// payloadVersion is written to every payload so the format can evolve.
+const payloadVersion = 1
+
+var (
+	// ErrMissingKey is returned when no application key is configured.
+	ErrMissingKey = errors.New("no application encryption key has been specified")
+	// ErrInvalidPayload is returned for payloads that are not produced by an Encrypter.
+	ErrInvalidPayload = errors.New("the payload is invalid")
+	// ErrInvalidMAC is returned when a payload was tampered with or encrypted with another key.
+	ErrInvalidMAC = errors.New("the MAC is invalid")
+)
+
+var keyLengths = map[string]int{AES128CBC: 16, AES256CBC: 32, AES128GCM: 16, AES256GCM: 32}
+
+// payload is the JSON document an encrypted value is stored in, base64 encoded.
+// CBC payloads are authenticated by mac, GCM payloads by tag, both covering
+// the version and cipher along with the IV and value.
+type payload struct {
+	Version int    `json:"v"`
+	IV      string `json:"iv"`
+	Value   string `json:"value"`
+	MAC     string `json:"mac"`
+	Tag     string `json:"tag"`
+}
+
+// Encrypter encrypts with its key and decrypts with its key or any of the
+// previous keys, so values survive a key rotation.
+type Encrypter struct {
+	cipher       string
+	key          []byte
+	previousKeys [][]byte
+}
+
+var (
+	defaultEncrypter atomic.Pointer[Encrypter]
+	defaultOnce      sync.Once
+)
+
+// New creates an Encrypter for one of the supported ciphers.
+func New(key []byte, cipherName string, previousKeys ...[]byte) (*Encrypter, error) {
+	cipherName = strings.ToUpper(cipherName)
+	length, ok := keyLengths[cipherName]
+	if !ok {
+		return nil, fmt.Errorf("unsupported cipher %q", cipherName)
+	}
+	for _, k := range append([][]byte{key}, previousKeys...) {
+		if len(k) != length {
+			return nil, fmt.Errorf("%s requires a %d byte key, got %d", cipherName, length, len(k))
+		}
+	}
+	return &Encrypter{cipher: cipherName, key: key, previousKeys: previousKeys}, nil
+}
+
+// Default returns the Encrypter built from app.key, app.cipher and
+// app.previous_keys. It is rebuilt when the configuration is reloaded.
+func Default() (*Encrypter, error) {
+	defaultOnce.Do(func() {
+		configs.OnChange("app", func(old, new interface{}) {
+			defaultEncrypter.Store(nil)
+		})
+	})
+
+	if e := defaultEncrypter.Load(); e != nil {
+		return e, nil
+	}
+
+	app := configs.App()
+	if app.Key == "" {
+		return nil, ErrMissingKey
+	}
+	key, err := ParseKey(app.Key)
+	if err != nil {
+		return nil, err
+	}
+	var previousKeys [][]byte
+	for _, previous := range app.PreviousKeys {
+		k, err := ParseKey(previous)
+		if err != nil {
+			return nil, err
+		}
+		previousKeys = append(previousKeys, k)
+	}
+
+	e, err := New(key, app.Cipher, previousKeys...)
+	if err != nil {
+		return nil, err
+	}
+	defaultEncrypter.Store(e)
+	return e, nil
+}
+
+// GenerateKey returns a random key for the cipher.
+func GenerateKey(cipherName string) ([]byte, error) {
+	length, ok := keyLengths[strings.ToUpper(cipherName)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported cipher %q", cipherName)
+	}
+	key := make([]byte, length)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// FormatKey encodes a key the way APP_KEY stores it, with a base64: prefix.
+func FormatKey(key []byte) string {
+	return "base64:" + base64.StdEncoding.EncodeToString(key)
+}
+
+// ParseKey decodes an APP_KEY value. Keys with a base64: prefix are decoded,
+// others are used as raw bytes.
+func ParseKey(key string) ([]byte, error) {
+	if encoded, ok := strings.CutPrefix(key, "base64:"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 key: %w", err)
+		}
+		return decoded, nil
+	}
+	return []byte(key), nil
+}
+
+// Encrypt serializes value as JSON and encrypts it.
+func (e *Encrypter) Encrypt(value interface{}) (string, error) {
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize value: %w", err)
+	}
+	return e.encrypt(serialized)
+}
+
+// Decrypt decrypts a payload produced by Encrypt into dst.
+func (e *Encrypter) Decrypt(encrypted string, dst interface{}) error {
+	plaintext, err := e.decrypt(encrypted)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(plaintext, dst); err != nil {
+		return fmt.Errorf("failed to unserialize value: %w", err)
+	}
+	return nil
+}
+
+// EncryptString encrypts a string without serializing it.
+func (e *Encrypter) EncryptString(value string) (string, error) {
+	return e.encrypt([]byte(value))
+}
+
+// DecryptString decrypts a payload produced by EncryptString.
+func (e *Encrypter) DecryptString(encrypted string) (string, error) {
+	plaintext, err := e.decrypt(encrypted)
+	return string(plaintext), err
+}
+
+// encrypt seals plaintext with the current key.
+func (e *Encrypter) encrypt(plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(e.key)
+	if err != nil {
+		return "", err
+	}
+
+	p := payload{Version: payloadVersion}
+	if e.isAEAD() {
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return "", err
+		}
+		iv, err := randomBytes(gcm.NonceSize())
+		if err != nil {
+			return "", err
+		}
+		sealed := gcm.Seal(nil, iv, plaintext, e.header(p.Version))
+		split := len(sealed) - gcm.Overhead()
+		p.IV = base64.StdEncoding.EncodeToString(iv)
+		p.Value = base64.StdEncoding.EncodeToString(sealed[:split])
+		p.Tag = base64.StdEncoding.EncodeToString(sealed[split:])
+	} else {
+		iv, err := randomBytes(aes.BlockSize)
+		if err != nil {
+			return "", err
+		}
+		padded := pad(plaintext, aes.BlockSize)
+		ciphertext := make([]byte, len(padded))
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, padded)
+		p.IV = base64.StdEncoding.EncodeToString(iv)
+		p.Value = base64.StdEncoding.EncodeToString(ciphertext)
+		p.MAC = mac(e.key, e.header(p.Version), p.IV, p.Value)
+	}
+
+	encoded, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encoded), nil
+}
+
+// decrypt opens a payload with the current key, then with the previous keys.
+func (e *Encrypter) decrypt(encrypted string) ([]byte, error) {
+	p, err := decodePayload(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, errIV := base64.StdEncoding.DecodeString(p.IV)
+	ciphertext, errValue := base64.StdEncoding.DecodeString(p.Value)
+	if errIV != nil || errValue != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	for _, key := range append([][]byte{e.key}, e.previousKeys...) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.isAEAD() {
+			tag, err := base64.StdEncoding.DecodeString(p.Tag)
+			if err != nil || p.Tag == "" {
+				return nil, ErrInvalidPayload
+			}
+			gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+			if err != nil {
+				return nil, ErrInvalidPayload
+			}
+			if plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), e.header(p.Version)); err == nil {
+				return plaintext, nil
+			}
+			continue
+		}
+
+		if !hmac.Equal([]byte(mac(key, e.header(p.Version), p.IV, p.Value)), []byte(p.MAC)) {
+			continue
+		}
+		if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, ErrInvalidPayload
+		}
+		plaintext := make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+		return unpad(plaintext, aes.BlockSize)
+	}
+
+	return nil, ErrInvalidMAC
+}
+
+// header is the authenticated data binding a payload to its version and cipher.
+func (e *Encrypter) header(version int) []byte {
+	return []byte(fmt.Sprintf("v%d:%s:", version, e.cipher))
+}
+
+// isAEAD reports whether the cipher authenticates the payload itself.
+func (e *Encrypter) isAEAD() bool {
+	return strings.HasSuffix(e.cipher, "-GCM")
+}
+
+// decodePayload parses and sanity-checks an encrypted payload.
+func decodePayload(encrypted string) (*payload, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, ErrInvalidPayload
+	}
+
+	p := &payload{}
+	if err := json.Unmarshal(decoded, p); err != nil || p.IV == "" || p.Value == "" {
+		return nil, ErrInvalidPayload
+	}
+	if p.Version != payloadVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidPayload, p.Version)
+	}
+	return p, nil
+}
+
+// mac authenticates the header, encoded IV and value of a CBC payload.
+func mac(key, header []byte, iv, value string) string {
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	h.Write([]byte(iv + value))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// randomBytes returns n cryptographically secure random bytes.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// pad applies PKCS#7 padding.
+func pad(data []byte, blockSize int) []byte {
+	n := blockSize - len(data)%blockSize
+	padded := make([]byte, len(data)+n)
+	copy(padded, data)
+	for i := len(data); i < len(padded); i++ {
+		padded[i] = byte(n)
+	}
+	return padded
+}
+
+// unpad removes PKCS#7 padding.
+func unpad(data []byte, blockSize int) ([]byte, error) {
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize || n > len(data) {
+		return nil, ErrInvalidPayload
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, ErrInvalidPayload
+		}
+	}
+	return data[:len(data)-n], nil
+}
+
+// Encrypt encrypts value with the default Encrypter.
+func Encrypt(value interface{}) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.Encrypt(value)
+}
+
+// Decrypt decrypts a payload into dst with the default Encrypter.
+func Decrypt(encrypted string, dst interface{}) error {
+	e, err := Default()
+	if err != nil {
+		return err
+	}
+	return e.Decrypt(encrypted, dst)
+}
+
+// EncryptString encrypts a string with the default Encrypter.
+func EncryptString(value string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.EncryptString(value)
+}
+
+// DecryptString decrypts a string with the default Encrypter.
+func DecryptString(encrypted string) (string, error) {
+	e, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return e.DecryptString(encrypted)
+}
@@ -0,0 +1,151 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func newEncrypter(t *testing.T, cipherName string, previousKeys ...[]byte) (*Encrypter, []byte) {
+	key, err := GenerateKey(cipherName)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	e, err := New(key, cipherName, previousKeys...)
+	if err != nil {
+		t.Fatalf("Failed to create encrypter: %v", err)
+	}
+	return e, key
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	for _, cipherName := range []string{AES128CBC, AES256CBC, AES128GCM, AES256GCM} {
+		e, _ := newEncrypter(t, cipherName)
+
+		encrypted, err := e.EncryptString("secret message")
+		if err != nil {
+			t.Fatalf("%s: failed to encrypt: %v", cipherName, err)
+		}
+		if decrypted, err := e.DecryptString(encrypted); err != nil || decrypted != "secret message" {
+			t.Errorf("%s: expected the original string, got %q (%v)", cipherName, decrypted, err)
+		}
+
+		encrypted, err = e.Encrypt(map[string]int{"user_id": 42})
+		if err != nil {
+			t.Fatalf("%s: failed to encrypt value: %v", cipherName, err)
+		}
+		var value map[string]int
+		if err := e.Decrypt(encrypted, &value); err != nil || value["user_id"] != 42 {
+			t.Errorf("%s: expected the original value, got %v (%v)", cipherName, value, err)
+		}
+	}
+}
+
+func TestEncryptProducesVersionedAuthenticatedPayloads(t *testing.T) {
+	cbc, _ := newEncrypter(t, AES256CBC)
+	encrypted, _ := cbc.EncryptString("value")
+	p := decode(t, encrypted)
+	if p.Version != payloadVersion || p.MAC == "" || p.Tag != "" {
+		t.Errorf("Expected a versioned CBC payload with a MAC, got %+v", p)
+	}
+
+	gcm, _ := newEncrypter(t, AES256GCM)
+	encrypted, _ = gcm.EncryptString("value")
+	p = decode(t, encrypted)
+	if p.Version != payloadVersion || p.MAC != "" || p.Tag == "" {
+		t.Errorf("Expected a versioned GCM payload with a tag, got %+v", p)
+	}
+
+	first, _ := cbc.EncryptString("value")
+	second, _ := cbc.EncryptString("value")
+	if first == second {
+		t.Error("Expected every encryption to use a fresh IV")
+	}
+}
+
+func TestDecryptRejectsTamperedPayloads(t *testing.T) {
+	for _, cipherName := range []string{AES256CBC, AES256GCM} {
+		e, _ := newEncrypter(t, cipherName)
+		encrypted, _ := e.EncryptString("value")
+
+		p := decode(t, encrypted)
+		ciphertext, _ := base64.StdEncoding.DecodeString(p.Value)
+		ciphertext[0] ^= 0xff
+		p.Value = base64.StdEncoding.EncodeToString(ciphertext)
+		tampered, _ := json.Marshal(p)
+
+		_, err := e.DecryptString(base64.StdEncoding.EncodeToString(tampered))
+		if !errors.Is(err, ErrInvalidMAC) {
+			t.Errorf("%s: expected ErrInvalidMAC, got %v", cipherName, err)
+		}
+
+		other, _ := newEncrypter(t, cipherName)
+		if _, err := other.DecryptString(encrypted); !errors.Is(err, ErrInvalidMAC) {
+			t.Errorf("%s: expected ErrInvalidMAC for another key, got %v", cipherName, err)
+		}
+	}
+
+	for _, cipherName := range []string{AES256CBC, AES256GCM} {
+		e, _ := newEncrypter(t, cipherName)
+		encrypted, _ := e.EncryptString("value")
+
+		for _, version := range []int{0, payloadVersion + 1} {
+			p := decode(t, encrypted)
+			p.Version = version
+			flipped, _ := json.Marshal(p)
+			if _, err := e.DecryptString(base64.StdEncoding.EncodeToString(flipped)); !errors.Is(err, ErrInvalidPayload) {
+				t.Errorf("%s: expected ErrInvalidPayload for version %d, got %v", cipherName, version, err)
+			}
+		}
+	}
+
+	e, _ := newEncrypter(t, AES256CBC)
+	if _, err := e.DecryptString("not a payload"); !errors.Is(err, ErrInvalidPayload) {
+		t.Errorf("Expected ErrInvalidPayload, got %v", err)
+	}
+}
+
+func TestDecryptWithPreviousKeys(t *testing.T) {
+	for _, cipherName := range []string{AES256CBC, AES256GCM} {
+		old, oldKey := newEncrypter(t, cipherName)
+		encrypted, _ := old.EncryptString("rotated")
+
+		rotated, _ := newEncrypter(t, cipherName, oldKey)
+		if decrypted, err := rotated.DecryptString(encrypted); err != nil || decrypted != "rotated" {
+			t.Errorf("%s: expected the previous key to decrypt, got %q (%v)", cipherName, decrypted, err)
+		}
+
+		// New values are always encrypted with the current key.
+		encrypted, _ = rotated.EncryptString("fresh")
+		if _, err := old.DecryptString(encrypted); err == nil {
+			t.Errorf("%s: expected the old key alone not to decrypt new values", cipherName)
+		}
+	}
+}
+
+func TestKeys(t *testing.T) {
+	key, _ := GenerateKey(AES256CBC)
+	parsed, err := ParseKey(FormatKey(key))
+	if err != nil || string(parsed) != string(key) {
+		t.Errorf("Expected a formatted key to parse back, got %v", err)
+	}
+	if _, err := New([]byte("short"), AES256GCM); err == nil {
+		t.Error("Expected an error for a key of the wrong length")
+	}
+	if _, err := New(key, "DES"); err == nil {
+		t.Error("Expected an error for an unsupported cipher")
+	}
+}
+
+func decode(t *testing.T, encrypted string) payload {
+	content, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		t.Fatalf("Expected a base64 payload: %v", err)
+	}
+	var p payload
+	if err := json.Unmarshal(content, &p); err != nil {
+		t.Fatalf("Expected a JSON payload: %v", err)
+	}
+	return p
+}
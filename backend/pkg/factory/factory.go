@@ -0,0 +1,143 @@
+// Package factory builds models filled with fake data for seeders and tests,
+// similar to Laravel's model factories.
+//
+//	users, err := factory.New[models.User]().Count(50).State(admin).Create(db)
+package factory
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/faker"
+
+	"gorm.io/gorm"
+)
+
+// Definition returns a model with the default fake attributes.
+type Definition[T any] func(f *faker.Faker) T
+
+var (
+	definitions   = map[reflect.Type]interface{}{}
+	definitionsMu sync.RWMutex
+)
+
+// Define registers the definition used by New for models of type T, usually
+// from the init function of the file declaring it.
+func Define[T any](definition Definition[T]) {
+	definitionsMu.Lock()
+	defer definitionsMu.Unlock()
+	definitions[reflect.TypeFor[T]()] = definition
+}
+
+// Factory makes models of type T. Its methods return a modified copy, so a
+// Factory can be shared and refined freely.
+type Factory[T any] struct {
+	count  int
+	states []func(m *T, f *faker.Faker)
+	seed   *uint64
+	locale string
+}
+
+// New returns a Factory making one model of type T with the definition
+// registered for T.
+func New[T any]() *Factory[T] {
+	return &Factory[T]{count: 1}
+}
+
+// Count sets how many models to make.
+func (f *Factory[T]) Count(n int) *Factory[T] {
+	c := f.clone()
+	c.count = n
+	return c
+}
+
+// State changes the models after the definition fills them in. States apply
+// in the order given.
+func (f *Factory[T]) State(state func(m *T, f *faker.Faker)) *Factory[T] {
+	c := f.clone()
+	c.states = append(c.states, state)
+	return c
+}
+
+// Seed makes the fake data reproducible: the same seed always makes the same models.
+func (f *Factory[T]) Seed(seed uint64) *Factory[T] {
+	c := f.clone()
+	c.seed = &seed
+	return c
+}
+
+// Locale overrides the configured faker locale.
+func (f *Factory[T]) Locale(locale string) *Factory[T] {
+	c := f.clone()
+	c.locale = locale
+	return c
+}
+
+// Make returns the models without saving them.
+func (f *Factory[T]) Make() ([]T, error) {
+	definitionsMu.RLock()
+	registered, ok := definitions[reflect.TypeFor[T]()]
+	definitionsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no factory is defined for %s", reflect.TypeFor[T]())
+	}
+	definition := registered.(Definition[T])
+
+	locale := f.locale
+	if locale == "" {
+		locale = configs.App().FakerLocale
+	}
+	fake := faker.New(locale)
+	if f.seed != nil {
+		fake = faker.NewSeeded(locale, *f.seed)
+	}
+
+	models := make([]T, f.count)
+	for i := range models {
+		models[i] = definition(fake)
+		for _, state := range f.states {
+			state(&models[i], fake)
+		}
+	}
+	return models, nil
+}
+
+// MakeOne returns a single model without saving it.
+func (f *Factory[T]) MakeOne() (T, error) {
+	models, err := f.Count(1).Make()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return models[0], nil
+}
+
+// Create makes the models and inserts them in one batch.
+func (f *Factory[T]) Create(db *gorm.DB) ([]T, error) {
+	models, err := f.Make()
+	if err != nil || len(models) == 0 {
+		return models, err
+	}
+	if err := db.Create(&models).Error; err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", reflect.TypeFor[T](), err)
+	}
+	return models, nil
+}
+
+// CreateOne makes a single model and inserts it.
+func (f *Factory[T]) CreateOne(db *gorm.DB) (T, error) {
+	models, err := f.Count(1).Create(db)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return models[0], nil
+}
+
+func (f *Factory[T]) clone() *Factory[T] {
+	c := *f
+	c.states = append([]func(m *T, f *faker.Faker){}, f.states...)
+	return &c
+}
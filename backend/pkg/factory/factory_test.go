@@ -0,0 +1,83 @@
+package factory
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"jazz/backend/pkg/faker"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type post struct {
+	ID        uint
+	Title     string
+	Author    string
+	Published bool
+}
+
+func init() {
+	Define(func(f *faker.Faker) post {
+		return post{Title: f.Sentence(4), Author: f.Name()}
+	})
+}
+
+func TestSeededFactoriesAreDeterministic(t *testing.T) {
+	posts := New[post]().Count(3).Seed(99).Locale("en_US")
+	first, err := posts.Make()
+	if err != nil {
+		t.Fatalf("Failed to make posts: %v", err)
+	}
+	second, _ := posts.Make()
+
+	if len(first) != 3 {
+		t.Fatalf("Expected 3 posts, got %d", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected the same seed to make the same posts, got %v and %v", first, second)
+	}
+}
+
+func TestStatesApplyInOrderWithoutChangingTheOriginal(t *testing.T) {
+	base := New[post]().Seed(1).Locale("en_US")
+	published := base.State(func(p *post, f *faker.Faker) { p.Published = true })
+	renamed := published.State(func(p *post, f *faker.Faker) { p.Title = "Release notes" })
+
+	p, err := renamed.MakeOne()
+	if err != nil {
+		t.Fatalf("Failed to make a post: %v", err)
+	}
+	if !p.Published || p.Title != "Release notes" {
+		t.Errorf("Expected both states to apply, got %+v", p)
+	}
+	if p, _ := base.MakeOne(); p.Published {
+		t.Error("Expected the base factory to be left unchanged")
+	}
+}
+
+func TestCreateInsertsTheModels(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "factory.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.AutoMigrate(&post{})
+
+	posts, err := New[post]().Count(5).Locale("en_US").Create(db)
+	if err != nil {
+		t.Fatalf("Failed to create posts: %v", err)
+	}
+	var count int64
+	db.Model(&post{}).Count(&count)
+	if count != 5 || posts[4].ID == 0 {
+		t.Errorf("Expected 5 saved posts with IDs, got %d rows and %+v", count, posts[4])
+	}
+}
+
+func TestUndefinedFactory(t *testing.T) {
+	type unknown struct{}
+	if _, err := New[unknown]().Make(); err == nil {
+		t.Error("Expected an error for a type without a definition")
+	}
+}
@@ -0,0 +1,253 @@
+// Package faker generates realistic fake data for seeders, factories and
+// tests, in the locale set by APP_FAKER_LOCALE.
+package faker
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode"
+
+	"jazz/backend/configs"
+)
+
+// Faker generates fake data from a random source. The same locale and seed
+// always produce the same values. A Faker is not safe for concurrent use.
+type Faker struct {
+	locale *locale
+	rand   *rand.Rand
+	// unique holds the values Unique returned, by name.
+	unique map[string]map[string]bool
+}
+
+// maxUniqueAttempts bounds how often Unique retries before giving up.
+const maxUniqueAttempts = 10000
+
+// New creates a Faker for a locale, seeded randomly. Unknown locales fall
+// back to en_US.
+func New(localeName string) *Faker {
+	return NewSeeded(localeName, rand.Uint64())
+}
+
+// NewSeeded creates a Faker whose values are reproducible from seed.
+func NewSeeded(localeName string, seed uint64) *Faker {
+	l, ok := locales[localeName]
+	if !ok {
+		l = locales["en_US"]
+	}
+	return &Faker{locale: l, rand: rand.New(rand.NewPCG(seed, seed))}
+}
+
+// Default creates a Faker for the configured faker locale.
+func Default() *Faker {
+	return New(configs.App().FakerLocale)
+}
+
+// Locale returns the name of the locale in use.
+func (f *Faker) Locale() string {
+	return f.locale.name
+}
+
+// Int returns a number between min and max, inclusive.
+func (f *Faker) Int(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + f.rand.IntN(max-min+1)
+}
+
+// Float returns a number between min and max.
+func (f *Faker) Float(min, max float64) float64 {
+	return min + f.rand.Float64()*(max-min)
+}
+
+// Bool returns true or false with even odds.
+func (f *Faker) Bool() bool {
+	return f.rand.IntN(2) == 1
+}
+
+// Element returns one of the values.
+func (f *Faker) Element(values ...string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[f.rand.IntN(len(values))]
+}
+
+// Numerify replaces every # in format with a random digit.
+func (f *Faker) Numerify(format string) string {
+	var b strings.Builder
+	for _, r := range format {
+		if r == '#' {
+			b.WriteByte(byte('0' + f.rand.IntN(10)))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Unique returns a value of generate that this Faker has not returned for
+// the same name before, such as f.Unique("username", (*Faker).Username) for
+// a column with a unique index. It panics when no new value turns up after
+// maxUniqueAttempts tries.
+func (f *Faker) Unique(name string, generate func(f *Faker) string) string {
+	if f.unique == nil {
+		f.unique = map[string]map[string]bool{}
+	}
+	seen := f.unique[name]
+	if seen == nil {
+		seen = map[string]bool{}
+		f.unique[name] = seen
+	}
+
+	for i := 0; i < maxUniqueAttempts; i++ {
+		if value := generate(f); !seen[value] {
+			seen[value] = true
+			return value
+		}
+	}
+	panic(fmt.Sprintf("faker: no unique %s left after %d attempts", name, maxUniqueAttempts))
+}
+
+// FirstName returns a first name.
+func (f *Faker) FirstName() string {
+	return f.Element(f.locale.firstNames...)
+}
+
+// LastName returns a last name.
+func (f *Faker) LastName() string {
+	return f.Element(f.locale.lastNames...)
+}
+
+// Name returns a full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username returns a username such as "maria.silva42", made of ASCII letters,
+// digits and dots.
+func (f *Faker) Username() string {
+	return fmt.Sprintf("%s.%s%d", slug(f.FirstName()), slug(f.LastName()), f.Int(1, 9999))
+}
+
+// Email returns an address at a free email provider.
+func (f *Faker) Email() string {
+	return f.Username() + "@" + f.Element(f.locale.emailDomains...)
+}
+
+// SafeEmail returns an address at a reserved example domain, which never
+// reaches a real mailbox.
+func (f *Faker) SafeEmail() string {
+	return f.Username() + "@" + f.Element("example.com", "example.org", "example.net")
+}
+
+// Password returns a random password of length characters.
+func (f *Faker) Password(length int) string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%&*"
+	b := make([]byte, length)
+	for i := range b {
+		b[i] = chars[f.rand.IntN(len(chars))]
+	}
+	return string(b)
+}
+
+// Phone returns a phone number in a local format.
+func (f *Faker) Phone() string {
+	return f.Numerify(f.Element(f.locale.phoneFormats...))
+}
+
+// StreetAddress returns a street and building number.
+func (f *Faker) StreetAddress() string {
+	return f.locale.streetAddress(f)
+}
+
+// City returns a city name.
+func (f *Faker) City() string {
+	return f.Element(f.locale.cities...)
+}
+
+// State returns a state abbreviation.
+func (f *Faker) State() string {
+	return f.Element(f.locale.states...)
+}
+
+// Postcode returns a postal code in the local format.
+func (f *Faker) Postcode() string {
+	return f.Numerify(f.locale.postcodeFormat)
+}
+
+// Company returns a company name.
+func (f *Faker) Company() string {
+	return f.LastName() + " " + f.Element(f.locale.companySuffixes...)
+}
+
+// Word returns a lorem ipsum word.
+func (f *Faker) Word() string {
+	return f.Element(loremWords...)
+}
+
+// Words returns n lorem ipsum words.
+func (f *Faker) Words(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = f.Word()
+	}
+	return words
+}
+
+// Sentence returns a sentence of about n words.
+func (f *Faker) Sentence(n int) string {
+	words := f.Words(max(1, f.Int(n*3/4, n*5/4)))
+	sentence := strings.Join(words, " ") + "."
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// Paragraph returns a paragraph of about n sentences.
+func (f *Faker) Paragraph(n int) string {
+	sentences := make([]string, max(1, f.Int(n*3/4, n*5/4)))
+	for i := range sentences {
+		sentences[i] = f.Sentence(8)
+	}
+	return strings.Join(sentences, " ")
+}
+
+// UUID returns a random version 4 UUID.
+func (f *Faker) UUID() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(f.rand.IntN(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// DateTimeBetween returns a time between start and end.
+func (f *Faker) DateTimeBetween(start, end time.Time) time.Time {
+	if !end.After(start) {
+		return start
+	}
+	return start.Add(time.Duration(f.rand.Int64N(int64(end.Sub(start)))))
+}
+
+// slug lowercases a name and strips its accents and anything but letters.
+func slug(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(accents.Replace(name)) {
+		if r < unicode.MaxASCII && unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a", "Á", "A", "Â", "A", "Ã", "A",
+	"é", "e", "ê", "e", "è", "e", "É", "E", "Ê", "E",
+	"í", "i", "î", "i", "Í", "I",
+	"ó", "o", "ô", "o", "õ", "o", "ö", "o", "Ó", "O", "Ô", "O", "Õ", "O",
+	"ú", "u", "ü", "u", "Ú", "U",
+	"ç", "c", "Ç", "C", "ñ", "n",
+)
@@ -0,0 +1,77 @@
+package faker
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSeededFakersAreReproducible(t *testing.T) {
+	a, b := NewSeeded("pt_BR", 42), NewSeeded("pt_BR", 42)
+	for i := 0; i < 20; i++ {
+		if x, y := a.Name()+a.Phone()+a.UUID(), b.Name()+b.Phone()+b.UUID(); x != y {
+			t.Fatalf("Expected the same values for the same seed, got %q and %q", x, y)
+		}
+	}
+
+	if NewSeeded("en_US", 1).Username() == NewSeeded("en_US", 2).Username() {
+		t.Error("Expected different seeds to produce different values")
+	}
+}
+
+func TestUnknownLocaleFallsBackToEnglish(t *testing.T) {
+	if got := NewSeeded("xx_XX", 1).Locale(); got != "en_US" {
+		t.Errorf("Expected en_US, got %s", got)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	f := NewSeeded("pt_BR", 7)
+	checks := map[string]struct {
+		value   string
+		pattern string
+	}{
+		"username": {f.Username(), `^[a-z]+\.[a-z]+\d+$`},
+		"email":    {f.SafeEmail(), `^[a-z]+\.[a-z]+\d+@example\.(com|org|net)$`},
+		"postcode": {f.Postcode(), `^\d{5}-\d{3}$`},
+		"uuid":     {f.UUID(), `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		"sentence": {f.Sentence(6), `^[A-Z][a-z ]+\.$`},
+	}
+	for name, check := range checks {
+		if !regexp.MustCompile(check.pattern).MatchString(check.value) {
+			t.Errorf("Expected %s %q to match %s", name, check.value, check.pattern)
+		}
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	if d := f.DateTimeBetween(start, end); d.Before(start) || !d.Before(end) {
+		t.Errorf("Expected %s to be within the range", d)
+	}
+	if n := f.Int(3, 5); n < 3 || n > 5 {
+		t.Errorf("Expected a number between 3 and 5, got %d", n)
+	}
+}
+
+func TestUniqueNeverRepeatsAValue(t *testing.T) {
+	f := NewSeeded("en_US", 1)
+
+	seen := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		username := f.Unique("username", (*Faker).Username)
+		if seen[username] {
+			t.Fatalf("Expected unique usernames, got %q twice", username)
+		}
+		seen[username] = true
+	}
+
+	coin := func(f *Faker) string { return f.Element("heads", "tails") }
+	f.Unique("coin", coin)
+	f.Unique("coin", coin)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Unique to panic once the values are exhausted")
+		}
+	}()
+	f.Unique("coin", coin)
+}
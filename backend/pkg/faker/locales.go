@@ -0,0 +1,89 @@
+package faker
+
+import "fmt"
+
+// locale holds the data a Faker draws from for one language and region.
+type locale struct {
+	name            string
+	firstNames      []string
+	lastNames       []string
+	cities          []string
+	states          []string
+	streets         []string
+	emailDomains    []string
+	phoneFormats    []string
+	postcodeFormat  string
+	companySuffixes []string
+	streetAddress   func(f *Faker) string
+}
+
+var locales = map[string]*locale{
+	"en_US": {
+		name: "en_US",
+		firstNames: []string{
+			"James", "Mary", "Robert", "Patricia", "John", "Jennifer", "Michael", "Linda", "David", "Elizabeth",
+			"William", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+			"Christopher", "Lisa", "Daniel", "Nancy", "Matthew", "Betty", "Anthony", "Sandra", "Mark", "Ashley",
+		},
+		lastNames: []string{
+			"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+			"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+			"Lee", "Perez", "Thompson", "White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson",
+		},
+		cities: []string{
+			"New York", "Los Angeles", "Chicago", "Houston", "Phoenix", "Philadelphia", "San Antonio", "San Diego",
+			"Dallas", "Austin", "Jacksonville", "Columbus", "Charlotte", "Indianapolis", "Seattle", "Denver", "Boston", "Portland",
+		},
+		states: []string{
+			"AL", "AK", "AZ", "CA", "CO", "CT", "FL", "GA", "IL", "IN", "MA", "MI", "MN", "NC", "NJ", "NY", "OH", "OR", "PA", "TX", "VA", "WA",
+		},
+		streets:         []string{"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake", "Hill", "Park", "Sunset", "Lincoln"},
+		emailDomains:    []string{"gmail.com", "yahoo.com", "hotmail.com", "outlook.com"},
+		phoneFormats:    []string{"(###) ###-####", "###-###-####", "+1 ###-###-####"},
+		postcodeFormat:  "#####",
+		companySuffixes: []string{"Inc", "LLC", "Group", "and Sons", "Ltd"},
+		streetAddress: func(f *Faker) string {
+			return fmt.Sprintf("%d %s %s", f.Int(1, 9999), f.Element(f.locale.streets...), f.Element("Street", "Avenue", "Road", "Lane", "Drive"))
+		},
+	},
+	"pt_BR": {
+		name: "pt_BR",
+		firstNames: []string{
+			"Miguel", "Arthur", "Heitor", "Bernardo", "Davi", "Théo", "Lorenzo", "Gabriel", "Pedro", "Benjamin",
+			"Matheus", "Lucas", "João", "Rafael", "Gustavo", "Helena", "Alice", "Laura", "Maria", "Valentina",
+			"Heloísa", "Júlia", "Beatriz", "Sophia", "Lívia", "Manuela", "Cecília", "Isabela", "Luíza", "Ana",
+		},
+		lastNames: []string{
+			"Silva", "Santos", "Oliveira", "Souza", "Rodrigues", "Ferreira", "Alves", "Pereira", "Lima", "Gomes",
+			"Costa", "Ribeiro", "Martins", "Carvalho", "Almeida", "Lopes", "Soares", "Fernandes", "Vieira", "Barbosa",
+			"Rocha", "Dias", "Nascimento", "Andrade", "Moreira", "Nunes", "Marques", "Machado", "Mendes", "Freitas",
+		},
+		cities: []string{
+			"São Paulo", "Rio de Janeiro", "Brasília", "Salvador", "Fortaleza", "Belo Horizonte", "Manaus", "Curitiba",
+			"Recife", "Goiânia", "Belém", "Porto Alegre", "Guarulhos", "Campinas", "São Luís", "Maceió", "Natal", "Florianópolis",
+		},
+		states: []string{
+			"AC", "AL", "AP", "AM", "BA", "CE", "DF", "ES", "GO", "MA", "MT", "MS", "MG", "PA", "PB", "PR", "PE", "PI", "RJ", "RN", "RS", "RO", "RR", "SC", "SP", "SE", "TO",
+		},
+		streets: []string{
+			"das Flores", "Sete de Setembro", "XV de Novembro", "Tiradentes", "Dom Pedro II", "São João",
+			"Santos Dumont", "Rui Barbosa", "Getúlio Vargas", "da Paz", "Marechal Deodoro", "das Palmeiras",
+		},
+		emailDomains:    []string{"gmail.com", "hotmail.com", "yahoo.com.br", "uol.com.br", "terra.com.br"},
+		phoneFormats:    []string{"(##) 9####-####", "(##) ####-####", "+55 ## 9####-####"},
+		postcodeFormat:  "#####-###",
+		companySuffixes: []string{"Ltda.", "S.A.", "e Filhos", "Comércio Ltda.", "ME"},
+		streetAddress: func(f *Faker) string {
+			return fmt.Sprintf("%s %s, %d", f.Element("Rua", "Avenida", "Travessa", "Alameda"), f.Element(f.locale.streets...), f.Int(1, 9999))
+		},
+	},
+}
+
+// loremWords are shared by every locale.
+var loremWords = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod",
+	"tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim", "ad", "minim",
+	"veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip", "ex", "ea",
+	"commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate", "velit", "esse",
+	"cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat", "non", "proident",
+}
@@ -0,0 +1,284 @@
+// Package hashing hashes and verifies passwords with bcrypt or argon2id,
+// similar to Laravel's Hash facade.
+package hashing
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"jazz/backend/configs"
+)
+
+// ErrMismatchedPassword is returned when a password does not match its hash.
+var ErrMismatchedPassword = errors.New("password does not match")
+
+// Hasher is a password hashing algorithm.
+type Hasher interface {
+	// Make hashes a password.
+	Make(password string) (string, error)
+	// Check reports whether a password matches a hash made by this algorithm.
+	Check(password, hash string) bool
+	// NeedsRehash reports whether a hash was made with other parameters.
+	NeedsRehash(hash string) bool
+	// Owns reports whether a hash was made by this algorithm.
+	Owns(hash string) bool
+}
+
+// Manager hashes with the configured driver and verifies hashes made by any
+// of its drivers, so stored hashes can move to another algorithm over time.
+type Manager struct {
+	driver  string
+	drivers map[string]Hasher
+}
+
+var (
+	defaultManager atomic.Pointer[Manager]
+	defaultOnce    sync.Once
+)
+
+// NewManager creates a Manager from the hashing configuration.
+func NewManager(cfg configs.HashingConfig) (*Manager, error) {
+	m := &Manager{
+		driver: cfg.Driver,
+		drivers: map[string]Hasher{
+			"bcrypt": &BcryptHasher{Cost: cfg.Bcrypt.Rounds},
+			"argon2id": &Argon2idHasher{
+				Memory:  uint32(cfg.Argon.Memory),
+				Time:    uint32(cfg.Argon.Time),
+				Threads: uint8(cfg.Argon.Threads),
+			},
+		},
+	}
+	if _, ok := m.drivers[m.driver]; !ok {
+		return nil, fmt.Errorf("unsupported hashing driver %q", m.driver)
+	}
+	return m, nil
+}
+
+// Default returns the Manager built from the hashing configuration. It is
+// rebuilt when the configuration is reloaded.
+func Default() (*Manager, error) {
+	defaultOnce.Do(func() {
+		configs.OnChange("hashing", func(old, new interface{}) {
+			defaultManager.Store(nil)
+		})
+	})
+
+	if m := defaultManager.Load(); m != nil {
+		return m, nil
+	}
+
+	m, err := NewManager(configs.Hashing())
+	if err != nil {
+		return nil, err
+	}
+	defaultManager.Store(m)
+	return m, nil
+}
+
+// Driver returns the hasher registered under name.
+func (m *Manager) Driver(name string) (Hasher, error) {
+	hasher, ok := m.drivers[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hashing driver %q", name)
+	}
+	return hasher, nil
+}
+
+// Make hashes a password with the default driver.
+func (m *Manager) Make(password string) (string, error) {
+	return m.drivers[m.driver].Make(password)
+}
+
+// Check reports whether a password matches a hash made by any driver.
+func (m *Manager) Check(password, hash string) bool {
+	for _, hasher := range m.drivers {
+		if hasher.Owns(hash) {
+			return hasher.Check(password, hash)
+		}
+	}
+	return false
+}
+
+// NeedsRehash reports whether a hash was made by another driver or with
+// outdated parameters, and should be replaced at the next login.
+func (m *Manager) NeedsRehash(hash string) bool {
+	hasher := m.drivers[m.driver]
+	return !hasher.Owns(hash) || hasher.NeedsRehash(hash)
+}
+
+// BcryptHasher hashes passwords with bcrypt.
+type BcryptHasher struct {
+	Cost int
+}
+
+// Make hashes a password with bcrypt.
+func (h *BcryptHasher) Make(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Check reports whether a password matches a bcrypt hash.
+func (h *BcryptHasher) Check(password, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash reports whether a bcrypt hash uses another cost.
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Owns reports whether hash is a bcrypt hash.
+func (h *BcryptHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Argon2idHasher hashes passwords with argon2id, encoded in the PHC string
+// format used by PHP: $argon2id$v=19$m=65536,t=4,p=1$salt$hash.
+type Argon2idHasher struct {
+	// Memory is in KiB.
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+}
+
+const (
+	argonSaltLength = 16
+	argonKeyLength  = 32
+	// argonLimit is how many times its own memory, time and threads a
+	// hasher lets a stored hash ask for, so a forged hash cannot make a
+	// check arbitrarily expensive.
+	argonLimit = 4
+)
+
+// argonHash is a decoded argon2id hash.
+type argonHash struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// Make hashes a password with argon2id and a random salt.
+func (h *Argon2idHasher) Make(password string) (string, error) {
+	salt := make([]byte, argonSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, argonKeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Check reports whether a password matches an argon2id hash, using the
+// parameters stored in the hash. Hashes asking for more than argonLimit
+// times the hasher's parameters never match.
+func (h *Argon2idHasher) Check(password, hash string) bool {
+	decoded, err := decodeArgonHash(hash)
+	if err != nil || !h.allows(decoded) {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), decoded.salt, decoded.time, decoded.memory, decoded.threads, uint32(len(decoded.key)))
+	return subtle.ConstantTimeCompare(key, decoded.key) == 1
+}
+
+// NeedsRehash reports whether an argon2id hash uses other parameters.
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	decoded, err := decodeArgonHash(hash)
+	if err != nil {
+		return true
+	}
+	return decoded.memory != h.Memory || decoded.time != h.Time || decoded.threads != h.Threads || len(decoded.key) != argonKeyLength
+}
+
+// allows reports whether the parameters of a decoded hash are within the
+// hasher's limits.
+func (h *Argon2idHasher) allows(decoded *argonHash) bool {
+	return uint64(decoded.memory) <= argonLimit*uint64(h.Memory) &&
+		uint64(decoded.time) <= argonLimit*uint64(h.Time) &&
+		uint64(decoded.threads) <= argonLimit*uint64(h.Threads) &&
+		len(decoded.key) <= argonLimit*argonKeyLength
+}
+
+// Owns reports whether hash is an argon2id hash.
+func (h *Argon2idHasher) Owns(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+// decodeArgonHash parses a PHC formatted argon2id hash.
+func decodeArgonHash(hash string) (*argonHash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	decoded := &argonHash{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &decoded.memory, &decoded.time, &decoded.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	// argon2.IDKey panics on zero time or threads.
+	if decoded.memory == 0 || decoded.time == 0 || decoded.threads == 0 {
+		return nil, fmt.Errorf("invalid argon2id parameters %q", parts[3])
+	}
+
+	var err error
+	if decoded.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	if decoded.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(decoded.key) == 0 {
+		return nil, errors.New("invalid argon2id key")
+	}
+	return decoded, nil
+}
+
+// Make hashes a password with the default Manager.
+func Make(password string) (string, error) {
+	m, err := Default()
+	if err != nil {
+		return "", err
+	}
+	return m.Make(password)
+}
+
+// Check reports whether a password matches a hash with the default Manager.
+// It is false when the Manager cannot be built.
+func Check(password, hash string) bool {
+	m, err := Default()
+	if err != nil {
+		return false
+	}
+	return m.Check(password, hash)
+}
+
+// NeedsRehash reports whether a hash should be replaced, using the default
+// Manager. It is false when the Manager cannot be built.
+func NeedsRehash(hash string) bool {
+	m, err := Default()
+	if err != nil {
+		return false
+	}
+	return m.NeedsRehash(hash)
+}
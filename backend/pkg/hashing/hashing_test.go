@@ -0,0 +1,100 @@
+package hashing
+
+import (
+	"strings"
+	"testing"
+
+	"jazz/backend/configs"
+)
+
+func testConfig(driver string) configs.HashingConfig {
+	return configs.HashingConfig{
+		Driver: driver,
+		Bcrypt: configs.BcryptConfig{Rounds: 4},
+		Argon:  configs.ArgonConfig{Memory: 1024, Threads: 1, Time: 1},
+	}
+}
+
+func TestMakeAndCheck(t *testing.T) {
+	for _, driver := range []string{"bcrypt", "argon2id"} {
+		m, err := NewManager(testConfig(driver))
+		if err != nil {
+			t.Fatalf("%s: failed to create manager: %v", driver, err)
+		}
+
+		hash, err := m.Make("secret")
+		if err != nil {
+			t.Fatalf("%s: failed to hash: %v", driver, err)
+		}
+		if !m.Check("secret", hash) {
+			t.Errorf("%s: expected the password to match", driver)
+		}
+		if m.Check("wrong", hash) {
+			t.Errorf("%s: expected a wrong password not to match", driver)
+		}
+		if m.NeedsRehash(hash) {
+			t.Errorf("%s: expected a fresh hash not to need rehashing", driver)
+		}
+	}
+}
+
+func TestArgon2idHashFormat(t *testing.T) {
+	h := &Argon2idHasher{Memory: 1024, Time: 1, Threads: 1}
+	hash, _ := h.Make("secret")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("Expected a PHC formatted hash, got %s", hash)
+	}
+	if h.Check("secret", "$argon2id$v=19$m=1024,t=1,p=1$broken") {
+		t.Error("Expected a malformed hash not to match")
+	}
+	for _, params := range []string{"m=0,t=1,p=1", "m=1024,t=0,p=1", "m=1024,t=1,p=0", "m=4294967295,t=1,p=1", "m=1024,t=4294967295,p=1", "m=1024,t=1,p=255"} {
+		forged := strings.Replace(hash, "m=1024,t=1,p=1", params, 1)
+		if h.Check("secret", forged) || !h.NeedsRehash(forged) {
+			t.Errorf("Expected a hash with %s to be rejected", params)
+		}
+	}
+
+	stronger, _ := (&Argon2idHasher{Memory: 2048, Time: 2, Threads: 2}).Make("secret")
+	if !h.Check("secret", stronger) {
+		t.Error("Expected a hash within the limits to keep verifying")
+	}
+}
+
+func TestNeedsRehashOnOutdatedParameters(t *testing.T) {
+	old, _ := NewManager(testConfig("bcrypt"))
+	hash, _ := old.Make("secret")
+
+	cfg := testConfig("bcrypt")
+	cfg.Bcrypt.Rounds = 5
+	stronger, _ := NewManager(cfg)
+	if !stronger.NeedsRehash(hash) {
+		t.Error("Expected a hash with a lower cost to need rehashing")
+	}
+
+	cfg = testConfig("argon2id")
+	cfg.Argon.Memory = 2048
+	argon, _ := NewManager(cfg)
+	argonHash, _ := argon.Make("secret")
+	cfg.Argon.Memory = 4096
+	upgraded, _ := NewManager(cfg)
+	if !upgraded.NeedsRehash(argonHash) {
+		t.Error("Expected an argon2id hash with less memory to need rehashing")
+	}
+}
+
+func TestMigrateBetweenDrivers(t *testing.T) {
+	bcryptManager, _ := NewManager(testConfig("bcrypt"))
+	hash, _ := bcryptManager.Make("secret")
+
+	argonManager, _ := NewManager(testConfig("argon2id"))
+	if !argonManager.Check("secret", hash) {
+		t.Error("Expected bcrypt hashes to keep verifying after switching to argon2id")
+	}
+	if !argonManager.NeedsRehash(hash) {
+		t.Error("Expected bcrypt hashes to need rehashing after switching to argon2id")
+	}
+
+	if _, err := NewManager(testConfig("md5")); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
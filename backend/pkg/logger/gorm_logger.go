@@ -3,9 +3,11 @@ package logger
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
@@ -59,7 +61,9 @@ func (g *GormLogger) Error(ctx context.Context, msg string, data ...interface{})
 	}
 }
 
-// Trace logs SQL queries along with their execution time.
+// Trace logs failed SQL queries as errors and the others at debug level.
+// Slow queries are reported by the database package, which knows the
+// connection and table they ran on.
 func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
 	if g.logLevel <= logger.Silent {
 		return
@@ -69,12 +73,9 @@ func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 	sql, rows := fc()
 
 	switch {
-	case err != nil && g.logLevel >= logger.Error:
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && g.logLevel >= logger.Error:
 		g.logger.Errorf("SQL Error: %s - %v [Rows affected: %d, Elapsed time: %v]", sql, err, rows, elapsed)
-	case elapsed > time.Millisecond*200 && g.logLevel >= logger.Warn:
-		// Log if the execution time is longer than 200ms
-		g.logger.Warnf("Slow SQL (> 200ms): %s [Rows affected: %d, Elapsed time: %v]", sql, rows, elapsed)
 	case g.logLevel >= logger.Info:
-		g.logger.Infof("SQL Executed: %s [Rows affected: %d, Elapsed time: %v]", sql, rows, elapsed)
+		g.logger.Debugw("SQL Executed", "sql", sql, "rows", rows, "elapsed", elapsed)
 	}
 }
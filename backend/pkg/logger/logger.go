@@ -3,8 +3,10 @@ package logger
 import (
 	"os"
 	"path/filepath"
+	"sync"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // AppLogger represents the logger instance.
@@ -16,6 +18,27 @@ type AppLogger struct {
 var Logger *AppLogger
 var isLoggerInitialized bool
 
+// logsPath is the directory holding app.log and the channel logs, relative
+// to the project root set with SetRoot.
+var logsPath = filepath.Join("storage", "logs")
+
+var (
+	channels   = map[string]*AppLogger{}
+	channelsMu sync.Mutex
+
+	// level is shared by the application logger and its channels, so
+	// SetLevel changes them all. defaultLevel is the level of APP_ENV.
+	level        = zap.NewAtomicLevel()
+	defaultLevel zapcore.Level
+)
+
+// SetRoot makes the log files relative to dir, the project root. The
+// configs package sets it on start-up; until then they are relative to the
+// working directory.
+func SetRoot(dir string) {
+	logsPath = filepath.Join(dir, "storage", "logs")
+}
+
 // InitializeLogger initializes the logger with different log levels for development and production.
 func InitializeLogger() {
 	if isLoggerInitialized {
@@ -23,7 +46,6 @@ func InitializeLogger() {
 	}
 
 	// Ensure the logs directory exists
-	logsPath := "../../storage/logs"
 	if err := os.MkdirAll(logsPath, os.ModePerm); err != nil {
 		panic("failed to create logs directory: " + err.Error())
 	}
@@ -42,10 +64,16 @@ func InitializeLogger() {
 	if env == "production" {
 		config := zap.NewProductionConfig()
 		config.OutputPaths = []string{"stdout", logFilePath}
+		defaultLevel = config.Level.Level()
+		level.SetLevel(defaultLevel)
+		config.Level = level
 		zapLogger, err = config.Build()
 	} else {
 		config := zap.NewDevelopmentConfig()
 		config.OutputPaths = []string{"stdout", logFilePath}
+		defaultLevel = config.Level.Level()
+		level.SetLevel(defaultLevel)
+		config.Level = level
 		zapLogger, err = config.Build()
 	}
 
@@ -60,7 +88,56 @@ func InitializeLogger() {
 	isLoggerInitialized = true
 }
 
-// Resto do código...
+// Channel returns the logger writing to storage/logs/<name>.log only, for
+// entries kept apart from app.log. Channels are created on first use.
+func Channel(name string) *AppLogger {
+	channelsMu.Lock()
+	defer channelsMu.Unlock()
+	if channel, ok := channels[name]; ok {
+		return channel
+	}
+
+	if err := os.MkdirAll(logsPath, os.ModePerm); err != nil {
+		GetLogger().Errorw("Failed to create logs directory, using the application log", "channel", name, "error", err)
+		return Logger
+	}
+	config := zap.NewDevelopmentConfig()
+	if os.Getenv("APP_ENV") == "production" {
+		config = zap.NewProductionConfig()
+	}
+	config.OutputPaths = []string{filepath.Join(logsPath, name+".log")}
+	config.Level = level
+	zapLogger, err := config.Build()
+	if err != nil {
+		GetLogger().Errorw("Failed to open log channel, using the application log", "channel", name, "error", err)
+		return Logger
+	}
+
+	channels[name] = &AppLogger{sugarLogger: zapLogger.Sugar().With("channel", name)}
+	return channels[name]
+}
+
+// SetLevel changes the lowest level logged by the application logger and
+// its channels, such as "debug" or "warn". An empty level restores the
+// default of APP_ENV.
+func SetLevel(name string) error {
+	InitializeLogger()
+	if name == "" {
+		level.SetLevel(defaultLevel)
+		return nil
+	}
+	l, err := zapcore.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(l)
+	return nil
+}
+
+// Level returns the lowest level logged, such as "info".
+func Level() string {
+	return level.Level().String()
+}
 
 // GetLogger retorna a instância singleton do logger, inicializando-a se necessário.
 func GetLogger() *AppLogger {
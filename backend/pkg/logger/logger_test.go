@@ -69,3 +69,22 @@ func TestFatal(t *testing.T) {
 
 	// Logger.Fatal("Esta é uma mensagem fatal para fins de teste")
 }
+
+func TestSetLevel(t *testing.T) {
+	InitializeLogger()
+	defaultLevel := Level()
+	defer SetLevel("")
+
+	if err := SetLevel("warn"); err != nil {
+		t.Fatalf("SetLevel(warn) failed: %v", err)
+	}
+	if Level() != "warn" {
+		t.Errorf("Expected level warn, got %s", Level())
+	}
+	if err := SetLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if err := SetLevel(""); err != nil || Level() != defaultLevel {
+		t.Errorf("Expected an empty level to restore %s, got %s (%v)", defaultLevel, Level(), err)
+	}
+}
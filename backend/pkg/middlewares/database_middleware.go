@@ -0,0 +1,45 @@
+// backend/pkg/middlewares/database_middleware.go
+package middlewares
+
+import (
+	"net/http"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/database"
+	"jazz/backend/pkg/logger"
+)
+
+// StickyWrites lets a request read its own writes: once it writes to a
+// primary, its reads on sticky connections go to the primary as well.
+func StickyWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(database.WithStickyWrites(r.Context())))
+	})
+}
+
+// CountQueries counts the queries each request runs and warns about the
+// ones repeated more than database.queries.repeat_threshold times, a likely
+// N+1 problem.
+func CountQueries(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, counter := database.WithQueryCounter(r.Context())
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		count, elapsed := counter.Count()
+		logger.Logger.Debugw("Request queries", "method", r.Method, "path", r.URL.Path, "queries", count, "elapsed", elapsed)
+
+		threshold := configs.Database().Queries.RepeatThreshold
+		if threshold <= 0 {
+			return
+		}
+		for query, times := range counter.Repeated(threshold) {
+			logger.Logger.Warnw("Query repeated in one request, possible N+1 problem",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"query", query,
+				"times", times,
+				"threshold", threshold,
+			)
+		}
+	})
+}
@@ -0,0 +1,317 @@
+// Package migration runs versioned schema migrations and records them in a
+// migrations table, similar to Laravel's migrator.
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/database"
+	"jazz/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a reversible schema change. Names sort in the order the
+// migrations run, so they start with a timestamp such as
+// 20241017014919_create_user_table.
+type Migration struct {
+	Name string
+	Up   func(tx *gorm.DB) error
+	Down func(tx *gorm.DB) error
+	// WithoutTransaction runs the migration outside of a transaction, for
+	// statements such as CREATE INDEX CONCURRENTLY.
+	WithoutTransaction bool
+}
+
+// Status tells whether a migration has run, and in which batch.
+type Status struct {
+	Name  string `json:"name"`
+	Ran   bool   `json:"ran"`
+	Batch int    `json:"batch,omitempty"`
+}
+
+// record is a row of the migrations table.
+type record struct {
+	ID        uint   `gorm:"primaryKey"`
+	Migration string `gorm:"size:255;not null"`
+	Batch     int    `gorm:"not null"`
+}
+
+var (
+	registry   = map[string]Migration{}
+	registryMu sync.RWMutex
+)
+
+// Register adds a migration to the registry, usually from the init function
+// of the file declaring it. It panics on an incomplete or duplicate migration.
+func Register(m Migration) {
+	if m.Name == "" || m.Up == nil || m.Down == nil {
+		panic(fmt.Sprintf("migration %q must have a name, Up and Down", m.Name))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[m.Name]; ok {
+		panic(fmt.Sprintf("migration %q is registered twice", m.Name))
+	}
+	registry[m.Name] = m
+}
+
+// Registered returns the registered migrations sorted by name.
+func Registered() []Migration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	migrations := make([]Migration, 0, len(registry))
+	for _, m := range registry {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Name < migrations[j].Name })
+	return migrations
+}
+
+// Migrator runs migrations against one connection.
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []Migration
+	// config and schemaPath locate the connection's schema dump, if any.
+	config     configs.ConnectionConfig
+	schemaPath string
+}
+
+// NewMigrator creates a Migrator recording its migrations in table.
+func NewMigrator(db *gorm.DB, table string, migrations []Migration) *Migrator {
+	return &Migrator{db: db, table: table, migrations: migrations}
+}
+
+// ForConnection creates a Migrator for the registered migrations on the named
+// connection, or on the default one when name is empty.
+func ForConnection(name string) (*Migrator, error) {
+	if name == "" {
+		name = configs.Database().Default
+	}
+	db, err := database.Connection(name)
+	if err != nil {
+		return nil, err
+	}
+	m := NewMigrator(db, configs.Database().Migrations.Table, Registered())
+	m.config, m.schemaPath = configs.Database().Connections[name], SchemaPath(name)
+	return m, nil
+}
+
+// Install creates the migrations table when it does not exist.
+func (m *Migrator) Install() error {
+	if err := m.db.Table(m.table).AutoMigrate(&record{}); err != nil {
+		return fmt.Errorf("failed to create the %s table: %w", m.table, err)
+	}
+	return nil
+}
+
+// Migrate runs the pending migrations in one batch, or in one batch each with
+// step, and returns the names of those that ran. When no migration has run
+// yet, the schema dump is loaded first and only newer migrations run.
+func (m *Migrator) Migrate(step bool) ([]string, error) {
+	if err := m.loadSchemaIfFresh(); err != nil {
+		return nil, err
+	}
+	ran, err := m.ran()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := 1
+	done := map[string]bool{}
+	for _, r := range ran {
+		done[r.Migration] = true
+		batch = max(batch, r.Batch+1)
+	}
+
+	var names []string
+	for _, migration := range m.migrations {
+		if done[migration.Name] {
+			continue
+		}
+		if err := m.up(migration, batch); err != nil {
+			return names, err
+		}
+		names = append(names, migration.Name)
+		if step {
+			batch++
+		}
+	}
+	return names, nil
+}
+
+// Rollback reverts the last batch, or the last steps migrations when steps is
+// positive, and returns the names of those reverted.
+func (m *Migrator) Rollback(steps int) ([]string, error) {
+	ran, err := m.ran()
+	if err != nil || len(ran) == 0 {
+		return nil, err
+	}
+
+	var last []record
+	if steps > 0 {
+		last = ran[:min(steps, len(ran))]
+	} else {
+		for _, r := range ran {
+			if r.Batch == ran[0].Batch {
+				last = append(last, r)
+			}
+		}
+	}
+	return m.rollback(last)
+}
+
+// Reset reverts every migration that has run.
+func (m *Migrator) Reset() ([]string, error) {
+	ran, err := m.ran()
+	if err != nil {
+		return nil, err
+	}
+	return m.rollback(ran)
+}
+
+// Refresh reverts every migration and runs them all again.
+func (m *Migrator) Refresh() ([]string, error) {
+	if _, err := m.Reset(); err != nil {
+		return nil, err
+	}
+	return m.Migrate(false)
+}
+
+// Fresh drops every table, without running the Down functions, and runs all
+// the migrations again.
+func (m *Migrator) Fresh() ([]string, error) {
+	if err := m.DropAllTables(); err != nil {
+		return nil, err
+	}
+	return m.Migrate(false)
+}
+
+// DropAllTables drops every table of the connection's database.
+func (m *Migrator) DropAllTables() error {
+	// Drivers turn foreign key checks off around drops, which only holds on
+	// the connection they run on
+	return m.db.Connection(func(conn *gorm.DB) error {
+		tables, err := conn.Migrator().GetTables()
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		for _, table := range tables {
+			// SQLite's internal tables cannot be dropped
+			if conn.Dialector.Name() == "sqlite" && strings.HasPrefix(table, "sqlite_") {
+				continue
+			}
+			if err := conn.Migrator().DropTable(table); err != nil {
+				return fmt.Errorf("failed to drop table %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration, registered or recorded, by name.
+func (m *Migrator) Status() ([]Status, error) {
+	ran, err := m.ran()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := map[string]int{}
+	for _, r := range ran {
+		batches[r.Migration] = r.Batch
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		batch, ok := batches[migration.Name]
+		statuses = append(statuses, Status{Name: migration.Name, Ran: ok, Batch: batch})
+		delete(batches, migration.Name)
+	}
+	// Migrations that ran but are no longer registered
+	for name, batch := range batches {
+		statuses = append(statuses, Status{Name: name, Ran: true, Batch: batch})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// ran returns the recorded migrations, the most recent first.
+func (m *Migrator) ran() ([]record, error) {
+	if err := m.Install(); err != nil {
+		return nil, err
+	}
+
+	var ran []record
+	if err := m.db.Table(m.table).Order("batch DESC").Order("migration DESC").Find(&ran).Error; err != nil {
+		return nil, fmt.Errorf("failed to read the %s table: %w", m.table, err)
+	}
+	return ran, nil
+}
+
+// rollback reverts the recorded migrations in the order given.
+func (m *Migrator) rollback(ran []record) ([]string, error) {
+	registered := map[string]Migration{}
+	for _, migration := range m.migrations {
+		registered[migration.Name] = migration
+	}
+
+	var names []string
+	for _, r := range ran {
+		migration, ok := registered[r.Migration]
+		if !ok {
+			return names, fmt.Errorf("migration %s is not registered", r.Migration)
+		}
+		if err := m.down(migration); err != nil {
+			return names, err
+		}
+		names = append(names, migration.Name)
+	}
+	return names, nil
+}
+
+// up runs a migration and records it in batch.
+func (m *Migrator) up(migration Migration, batch int) error {
+	return m.run(migration, "up", func(tx *gorm.DB) error {
+		if err := migration.Up(tx); err != nil {
+			return err
+		}
+		return tx.Table(m.table).Create(&record{Migration: migration.Name, Batch: batch}).Error
+	})
+}
+
+// down reverts a migration and removes its record.
+func (m *Migrator) down(migration Migration) error {
+	return m.run(migration, "down", func(tx *gorm.DB) error {
+		if err := migration.Down(tx); err != nil {
+			return err
+		}
+		return tx.Table(m.table).Where("migration = ?", migration.Name).Delete(&record{}).Error
+	})
+}
+
+// run applies fn in a transaction when the dialect can roll back schema
+// changes. MySQL commits implicitly after DDL statements, so it runs without.
+func (m *Migrator) run(migration Migration, direction string, fn func(tx *gorm.DB) error) error {
+	start := time.Now()
+
+	var err error
+	if migration.WithoutTransaction || m.db.Dialector.Name() == "mysql" {
+		err = fn(m.db)
+	} else {
+		err = m.db.Transaction(fn)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %s (%s): %w", migration.Name, direction, err)
+	}
+
+	logger.Logger.Infow("Ran migration", "migration", migration.Name, "direction", direction, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,344 @@
+package migration
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"jazz/backend/configs"
+	"jazz/backend/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// SchemaPath returns the schema dump file of the named connection.
+func SchemaPath(connection string) string {
+	return configs.BasePath("backend", "database", "schema", connection+".sql")
+}
+
+// DumpSchema writes the database schema, along with the records of the
+// migrations table, to the migrator's schema file. A fresh database loads
+// it instead of running the migrations it contains. MySQL and PostgreSQL
+// dumps need mysqldump or pg_dump on the PATH.
+func (m *Migrator) DumpSchema() (string, error) {
+	if m.schemaPath == "" {
+		return "", errors.New("the migrator has no schema file")
+	}
+	if err := m.Install(); err != nil {
+		return "", err
+	}
+
+	var dump string
+	var err error
+	switch dialect := m.db.Dialector.Name(); dialect {
+	case "sqlite":
+		dump, err = m.dumpSQLite()
+	case "mysql":
+		dump, err = m.dumpMySQL()
+	case "postgres":
+		dump, err = m.dumpPostgres()
+	default:
+		err = fmt.Errorf("schema dumps are not supported on %s", dialect)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.schemaPath), 0755); err != nil {
+		return "", err
+	}
+	return m.schemaPath, os.WriteFile(m.schemaPath, []byte(dump), 0644)
+}
+
+// PruneMigrations deletes the files in dir of the migrations that have run,
+// which a schema dump replaces, and returns their names. The binaries built
+// afterwards no longer register them.
+func (m *Migrator) PruneMigrations(dir string) ([]string, error) {
+	ran, err := m.ran()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []string
+	for i := len(ran) - 1; i >= 0; i-- {
+		err := os.Remove(filepath.Join(dir, ran[i].Migration+".go"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, ran[i].Migration)
+	}
+	return pruned, nil
+}
+
+// loadSchemaIfFresh loads the schema file when it exists and no migration
+// has run yet, replacing the migrations it recorded.
+func (m *Migrator) loadSchemaIfFresh() error {
+	if m.schemaPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(m.schemaPath); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	ran, err := m.ran()
+	if err != nil || len(ran) > 0 {
+		return err
+	}
+
+	switch dialect := m.db.Dialector.Name(); dialect {
+	case "sqlite":
+		err = m.loadSQLite()
+	case "mysql":
+		if err = m.dropMigrationsTable(m.db); err == nil {
+			err = m.loadMySQL()
+		}
+	case "postgres":
+		if err = m.dropMigrationsTable(m.db); err == nil {
+			err = m.loadPostgres()
+		}
+	default:
+		err = fmt.Errorf("schema dumps are not supported on %s", dialect)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load the schema from %s: %w", m.schemaPath, err)
+	}
+
+	logger.Logger.Infow("Loaded the database schema", "path", m.schemaPath)
+	return nil
+}
+
+// dropMigrationsTable drops the empty migrations table, which the dump
+// creates along with its records.
+func (m *Migrator) dropMigrationsTable(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(m.table); err != nil {
+		return fmt.Errorf("failed to drop the %s table: %w", m.table, err)
+	}
+	return nil
+}
+
+// loadSQLite replays the dump in a transaction, so a failing statement
+// leaves the database as it was.
+func (m *Migrator) loadSQLite() error {
+	dump, err := os.ReadFile(m.schemaPath)
+	if err != nil {
+		return err
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.dropMigrationsTable(tx); err != nil {
+			return err
+		}
+		// The SQLite driver runs every statement of a multi-statement string
+		return tx.Exec(string(dump)).Error
+	})
+}
+
+// dumpSQLite reads the schema from sqlite_master, in creation order.
+func (m *Migrator) dumpSQLite() (string, error) {
+	var statements []string
+	err := m.db.Raw("SELECT sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' ORDER BY rowid").Scan(&statements).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to read the schema: %w", err)
+	}
+
+	ran, err := m.ran()
+	if err != nil {
+		return "", err
+	}
+	sort.Slice(ran, func(i, j int) bool { return ran[i].ID < ran[j].ID })
+
+	var b strings.Builder
+	for _, statement := range statements {
+		b.WriteString(statement + ";\n")
+	}
+	for _, r := range ran {
+		fmt.Fprintf(&b, "INSERT INTO \"%s\" (\"id\", \"migration\", \"batch\") VALUES (%d, '%s', %d);\n",
+			m.table, r.ID, strings.ReplaceAll(r.Migration, "'", "''"), r.Batch)
+	}
+	return b.String(), nil
+}
+
+// autoIncrement matches the counters mysqldump writes in table options.
+var autoIncrement = regexp.MustCompile(`\s+AUTO_INCREMENT=\d+`)
+
+func (m *Migrator) dumpMySQL() (string, error) {
+	t, err := dumpTargetFor(m.config)
+	if err != nil {
+		return "", err
+	}
+	options := append(t.mysqlArgs(), "--no-tablespaces", "--skip-add-locks", "--skip-comments", "--skip-set-charset", "--tz-utc")
+
+	schema, err := run("mysqldump", t.mysqlEnv(), nil, slices.Concat(options, []string{"--no-data", "--routines", t.database})...)
+	if err != nil {
+		return "", err
+	}
+	records, err := run("mysqldump", t.mysqlEnv(), nil, slices.Concat(options, []string{"--no-create-info", "--skip-extended-insert", "--skip-routines", "--compact", t.database, m.table})...)
+	if err != nil {
+		return "", err
+	}
+	return autoIncrement.ReplaceAllString(schema, "") + records, nil
+}
+
+func (m *Migrator) loadMySQL() error {
+	t, err := dumpTargetFor(m.config)
+	if err != nil {
+		return err
+	}
+	dump, err := os.Open(m.schemaPath)
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+	_, err = run("mysql", t.mysqlEnv(), dump, append(t.mysqlArgs(), "--database="+t.database)...)
+	return err
+}
+
+func (m *Migrator) dumpPostgres() (string, error) {
+	t, err := dumpTargetFor(m.config)
+	if err != nil {
+		return "", err
+	}
+	options := append(t.postgresArgs(), "--no-owner", "--no-acl")
+
+	schema, err := run("pg_dump", t.postgresEnv(), nil, slices.Concat(options, []string{"--schema-only"})...)
+	if err != nil {
+		return "", err
+	}
+	records, err := run("pg_dump", t.postgresEnv(), nil, slices.Concat(options, []string{"--data-only", "--table=" + m.table})...)
+	if err != nil {
+		return "", err
+	}
+	return schema + records, nil
+}
+
+func (m *Migrator) loadPostgres() error {
+	t, err := dumpTargetFor(m.config)
+	if err != nil {
+		return err
+	}
+	_, err = run("psql", t.postgresEnv(), nil, append(t.postgresArgs(), "--quiet", "--single-transaction", "--set=ON_ERROR_STOP=1", "--file="+m.schemaPath)...)
+	return err
+}
+
+// dumpTarget is the server the command-line tools connect to.
+type dumpTarget struct {
+	host     string
+	port     int
+	socket   string
+	username string
+	password string
+	database string
+	params   url.Values
+}
+
+// dumpTargetFor returns the primary server of a connection.
+func dumpTargetFor(cfg configs.ConnectionConfig) (dumpTarget, error) {
+	t := dumpTarget{
+		host:     cfg.Host,
+		port:     cfg.Port,
+		socket:   cfg.UnixSocket,
+		username: cfg.Username,
+		password: cfg.Password,
+		database: cfg.Database,
+	}
+	if len(cfg.Write.Host) > 0 {
+		t.host = cfg.Write.Host[0]
+		if cfg.Write.Port != 0 {
+			t.port = cfg.Write.Port
+		}
+		if cfg.Write.Username != "" {
+			t.username, t.password = cfg.Write.Username, cfg.Write.Password
+		}
+	}
+	if cfg.URL == "" {
+		return t, nil
+	}
+
+	// The tools take the URL's parts as flags, keeping the password out of
+	// the process list, and the URL may use a scheme they do not accept.
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return t, fmt.Errorf("invalid database url: %w", err)
+	}
+	t.host = u.Hostname()
+	if port, err := strconv.Atoi(u.Port()); err == nil {
+		t.port = port
+	}
+	if u.User != nil {
+		t.username = u.User.Username()
+		t.password, _ = u.User.Password()
+	}
+	if database := strings.TrimPrefix(u.Path, "/"); database != "" {
+		t.database = database
+	}
+	t.params = u.Query()
+	return t, nil
+}
+
+func (t dumpTarget) mysqlArgs() []string {
+	args := []string{"--user=" + t.username}
+	if t.socket != "" {
+		return append(args, "--socket="+t.socket)
+	}
+	return append(args, "--host="+t.host, "--port="+strconv.Itoa(t.port))
+}
+
+// mysqlEnv passes the password through the environment, out of the
+// process list.
+func (t dumpTarget) mysqlEnv() []string {
+	return []string{"MYSQL_PWD=" + t.password}
+}
+
+func (t dumpTarget) postgresArgs() []string {
+	return []string{"--host=" + t.host, "--port=" + strconv.Itoa(t.port), "--username=" + t.username, "--dbname=" + t.database}
+}
+
+// postgresParams maps the URL parameters libpq also reads from the
+// environment to their variables.
+var postgresParams = map[string]string{
+	"sslmode":          "PGSSLMODE",
+	"sslrootcert":      "PGSSLROOTCERT",
+	"sslcert":          "PGSSLCERT",
+	"sslkey":           "PGSSLKEY",
+	"connect_timeout":  "PGCONNECT_TIMEOUT",
+	"application_name": "PGAPPNAME",
+}
+
+// postgresEnv passes the password and the URL's connection parameters
+// through the environment.
+func (t dumpTarget) postgresEnv() []string {
+	env := []string{"PGPASSWORD=" + t.password}
+	for param, variable := range postgresParams {
+		if value := t.params.Get(param); value != "" {
+			env = append(env, variable+"="+value)
+		}
+	}
+	sort.Strings(env[1:])
+	return env
+}
+
+// run runs a command-line tool and returns its output.
+func run(name string, env []string, stdin io.Reader, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = stdin, &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
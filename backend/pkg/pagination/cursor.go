@@ -0,0 +1,142 @@
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned for a cursor this package did not encode.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the position a cursor page starts after, or before when
+// backward. Clients only see it encoded.
+type cursor struct {
+	Value    interface{} `json:"v"`
+	Backward bool        `json:"b,omitempty"`
+}
+
+func (c cursor) encode() (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	var c cursor
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&c); err != nil || c.Value == nil {
+		return c, ErrInvalidCursor
+	}
+	// Compare numbers as numbers, not as the strings JSON keeps them in
+	if n, ok := c.Value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			c.Value = i
+		} else if f, err := n.Float64(); err == nil {
+			c.Value = f
+		} else {
+			return c, ErrInvalidCursor
+		}
+	}
+	return c, nil
+}
+
+// Cursor returns the page of query's records after, or before, the
+// request's cursor, ordered by column. column must be unique and is "id"
+// when empty; query must not be ordered otherwise. An unreadable cursor
+// returns ErrInvalidCursor.
+func Cursor[T any](query *gorm.DB, r *http.Request, column string) (*Page[T], error) {
+	if column == "" {
+		column = "id"
+	}
+	params := FromRequest(r)
+	position := cursor{}
+	if params.Cursor != "" {
+		var err error
+		if position, err = decodeCursor(params.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return nil, fmt.Errorf("%s has no column %q to paginate by", stmt.Schema.Name, column)
+	}
+
+	col := clause.Column{Name: column}
+	query = query.Model(new(T)).Order(clause.OrderByColumn{Column: col, Desc: position.Backward})
+	if position.Value != nil {
+		if position.Backward {
+			query = query.Where(clause.Lt{Column: col, Value: position.Value})
+		} else {
+			query = query.Where(clause.Gt{Column: col, Value: position.Value})
+		}
+	}
+
+	// One extra record tells whether there is a further page
+	data := make([]T, 0, params.PerPage+1)
+	if err := query.Limit(params.PerPage + 1).Find(&data).Error; err != nil {
+		return nil, err
+	}
+	more := len(data) > params.PerPage
+	if more {
+		data = data[:params.PerPage]
+	}
+	if position.Backward {
+		slices.Reverse(data)
+	}
+
+	page := &Page[T]{
+		Data:  data,
+		Meta:  Meta{PerPage: params.PerPage},
+		Links: Links{First: link(r, "cursor", "")},
+	}
+	if len(data) == 0 {
+		return page, nil
+	}
+
+	valueAt := func(i int) interface{} {
+		value, _ := field.ValueOf(query.Statement.Context, reflect.ValueOf(&data[i]).Elem())
+		return value
+	}
+	// Going backward, the page we came from follows; going forward, any
+	// cursor means a page precedes
+	hasNext, hasPrev := more, position.Value != nil
+	if position.Backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		next, err := cursor{Value: valueAt(len(data) - 1)}.encode()
+		if err != nil {
+			return nil, err
+		}
+		page.Meta.NextCursor, page.Links.Next = next, link(r, "cursor", next)
+	}
+	if hasPrev {
+		prev, err := cursor{Value: valueAt(0), Backward: true}.encode()
+		if err != nil {
+			return nil, err
+		}
+		page.Meta.PrevCursor, page.Links.Prev = prev, link(r, "cursor", prev)
+	}
+	return page, nil
+}
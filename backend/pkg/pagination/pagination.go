@@ -0,0 +1,133 @@
+// Package pagination splits GORM queries into pages for list endpoints and
+// renders them in a standard JSON envelope:
+//
+//	{"data": [...], "meta": {"per_page": 15, ...}, "links": {"next": "/users?page=2", ...}}
+//
+// Offset pages count the matching records and can jump to any page. Cursor
+// pages skip the count and scale to large tables, but only move one page
+// forward or backward at a time.
+package pagination
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultPerPage is the page size when the request does not set per_page.
+	DefaultPerPage = 15
+	// MaxPerPage caps the page size a request can ask for.
+	MaxPerPage = 100
+)
+
+// Params are the pagination parameters of a request.
+type Params struct {
+	Page    int
+	PerPage int
+	Cursor  string
+}
+
+// FromRequest reads the page, per_page and cursor query parameters. Missing
+// or invalid numbers fall back to the first page of DefaultPerPage records,
+// and per_page is capped at MaxPerPage.
+func FromRequest(r *http.Request) Params {
+	query := r.URL.Query()
+	params := Params{Page: 1, PerPage: DefaultPerPage, Cursor: query.Get("cursor")}
+	if page, err := strconv.Atoi(query.Get("page")); err == nil && page > 0 {
+		params.Page = page
+	}
+	if perPage, err := strconv.Atoi(query.Get("per_page")); err == nil && perPage > 0 {
+		params.PerPage = min(perPage, MaxPerPage)
+	}
+	return params
+}
+
+// Page is one page of records in the JSON envelope.
+type Page[T any] struct {
+	Data  []T   `json:"data"`
+	Meta  Meta  `json:"meta"`
+	Links Links `json:"links"`
+}
+
+// Meta describes the page. Offset pages fill in the page numbers and total,
+// cursor pages the cursors.
+type Meta struct {
+	PerPage     int    `json:"per_page"`
+	CurrentPage int    `json:"current_page,omitempty"`
+	LastPage    int    `json:"last_page,omitempty"`
+	From        int    `json:"from,omitempty"`
+	To          int    `json:"to,omitempty"`
+	Total       *int64 `json:"total,omitempty"`
+	NextCursor  string `json:"next_cursor,omitempty"`
+	PrevCursor  string `json:"prev_cursor,omitempty"`
+}
+
+// Links are the URLs of the neighbouring pages, null when there is none.
+// They keep the request's other query parameters.
+type Links struct {
+	First *string `json:"first"`
+	Last  *string `json:"last"`
+	Prev  *string `json:"prev"`
+	Next  *string `json:"next"`
+}
+
+// Write renders the page as the JSON response.
+func (p *Page[T]) Write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Offset returns the page of query's records the request asks for with
+// page and per_page, along with the total count.
+func Offset[T any](query *gorm.DB, r *http.Request) (*Page[T], error) {
+	params := FromRequest(r)
+	query = query.Model(new(T)).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	data := make([]T, 0, params.PerPage)
+	if err := query.Offset((params.Page - 1) * params.PerPage).Limit(params.PerPage).Find(&data).Error; err != nil {
+		return nil, err
+	}
+
+	lastPage := max(1, int((total+int64(params.PerPage)-1)/int64(params.PerPage)))
+	page := &Page[T]{
+		Data: data,
+		Meta: Meta{PerPage: params.PerPage, CurrentPage: params.Page, LastPage: lastPage, Total: &total},
+		Links: Links{
+			First: link(r, "page", "1"),
+			Last:  link(r, "page", strconv.Itoa(lastPage)),
+		},
+	}
+	if len(data) > 0 {
+		page.Meta.From = (params.Page-1)*params.PerPage + 1
+		page.Meta.To = page.Meta.From + len(data) - 1
+	}
+	if params.Page > 1 {
+		page.Links.Prev = link(r, "page", strconv.Itoa(min(params.Page-1, lastPage)))
+	}
+	if params.Page < lastPage {
+		page.Links.Next = link(r, "page", strconv.Itoa(params.Page+1))
+	}
+	return page, nil
+}
+
+// link returns the request's URL with the query parameter key set to
+// value, and the parameter of the other pagination mode removed.
+func link(r *http.Request, key, value string) *string {
+	query := r.URL.Query()
+	query.Del("page")
+	query.Del("cursor")
+	if value != "" {
+		query.Set(key, value)
+	}
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	s := u.String()
+	return &s
+}
@@ -0,0 +1,128 @@
+package pagination
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func seed(t *testing.T, n int) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "pagination.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	for i := 1; i <= n; i++ {
+		db.Create(&item{Name: fmt.Sprintf("item %d", i)})
+	}
+	return db
+}
+
+func ids(items []item) []uint {
+	ids := make([]uint, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	return ids
+}
+
+func TestFromRequest(t *testing.T) {
+	tests := map[string]Params{
+		"/items":                        {Page: 1, PerPage: DefaultPerPage},
+		"/items?page=3&per_page=20":     {Page: 3, PerPage: 20},
+		"/items?page=-1&per_page=abc":   {Page: 1, PerPage: DefaultPerPage},
+		"/items?per_page=1000&cursor=x": {Page: 1, PerPage: MaxPerPage, Cursor: "x"},
+	}
+	for target, want := range tests {
+		if got := FromRequest(httptest.NewRequest("GET", target, nil)); got != want {
+			t.Errorf("%s: expected %+v, got %+v", target, want, got)
+		}
+	}
+}
+
+func TestOffset(t *testing.T) {
+	db := seed(t, 12)
+
+	page, err := Offset[item](db.Where("id > ?", 1), httptest.NewRequest("GET", "/items?page=2&per_page=5&sort=name", nil))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := ids(page.Data); len(got) != 5 || got[0] != 7 {
+		t.Errorf("Expected items 7 to 11, got %v", got)
+	}
+	meta := page.Meta
+	if *meta.Total != 11 || meta.LastPage != 3 || meta.From != 6 || meta.To != 10 {
+		t.Errorf("Unexpected meta %+v", meta)
+	}
+	if *page.Links.Prev != "/items?page=1&per_page=5&sort=name" || *page.Links.Next != "/items?page=3&per_page=5&sort=name" {
+		t.Errorf("Unexpected links %s and %s", *page.Links.Prev, *page.Links.Next)
+	}
+
+	rec := httptest.NewRecorder()
+	empty, _ := Offset[item](db.Where("id > ?", 100), httptest.NewRequest("GET", "/items", nil))
+	if err := empty.Write(rec); err != nil {
+		t.Fatalf("Failed to write page: %v", err)
+	}
+	want := `{"data":[],"meta":{"per_page":15,"current_page":1,"last_page":1,"total":0},"links":{"first":"/items?page=1","last":"/items?page=1","prev":null,"next":null}}` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, rec.Body.String())
+	}
+}
+
+func TestCursorWalksForwardAndBackward(t *testing.T) {
+	db := seed(t, 7)
+	get := func(target string) *Page[item] {
+		t.Helper()
+		page, err := Cursor[item](db, httptest.NewRequest("GET", target, nil), "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", target, err)
+		}
+		return page
+	}
+
+	first := get("/items?per_page=3")
+	if got := ids(first.Data); len(got) != 3 || got[0] != 1 || first.Links.Prev != nil || first.Links.Next == nil {
+		t.Fatalf("Unexpected first page %v, %+v", got, first.Links)
+	}
+	second := get(*first.Links.Next)
+	if got := ids(second.Data); len(got) != 3 || got[0] != 4 || second.Meta.PrevCursor == "" {
+		t.Fatalf("Unexpected second page %v", got)
+	}
+	last := get(*second.Links.Next)
+	if got := ids(last.Data); len(got) != 1 || got[0] != 7 || last.Links.Next != nil {
+		t.Fatalf("Unexpected last page %v, next %v", got, last.Links.Next)
+	}
+	back := get(*last.Links.Prev)
+	if got := ids(back.Data); len(got) != 3 || got[0] != 4 || back.Links.Next == nil || back.Links.Prev == nil {
+		t.Fatalf("Expected to go back to items 4 to 6, got %v", got)
+	}
+	if start := get(*back.Links.Prev); start.Links.Prev != nil || ids(start.Data)[0] != 1 {
+		t.Errorf("Expected to go back to the first page, got %v", ids(start.Data))
+	}
+
+	// Cursors are opaque to clients
+	var payload map[string]interface{}
+	if json.Unmarshal([]byte(second.Meta.NextCursor), &payload) == nil {
+		t.Error("Expected the cursor to be encoded")
+	}
+	if _, err := Cursor[item](db, httptest.NewRequest("GET", "/items?cursor=garbage!", nil), ""); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := Cursor[item](db, httptest.NewRequest("GET", "/items", nil), "missing"); err == nil {
+		t.Error("Expected an error for an unknown column")
+	}
+}
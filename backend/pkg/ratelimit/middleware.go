@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"jazz/backend/pkg/middlewares"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// KeyFunc extracts the identity a request is throttled by.
+type KeyFunc func(r *http.Request) string
+
+// ByIP throttles requests per client IP address.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByUser throttles requests per authenticated user, falling back to the client IP for guests.
+func ByUser(r *http.Request) string {
+	if userID, ok := r.Context().Value(middlewares.UserContextKey).(uint); ok {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "ip:" + ByIP(r)
+}
+
+// ByRoute throttles requests per route and user, so each endpoint has its own budget.
+func ByRoute(r *http.Request) string {
+	route := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+	return r.Method + " " + route + "|" + ByUser(r)
+}
+
+// Option customizes a Throttle middleware.
+type Option func(*throttle)
+
+// WithAlgorithm counts hits with the given algorithm instead of a fixed window.
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(t *throttle) {
+		t.algorithm = algorithm
+	}
+}
+
+// WithKey throttles requests by the identity returned by fn.
+func WithKey(fn KeyFunc) Option {
+	return func(t *throttle) {
+		t.key = fn
+	}
+}
+
+// WithLimiter stores hits in limiter instead of the default one.
+func WithLimiter(limiter *Limiter) Option {
+	return func(t *throttle) {
+		t.limiter = limiter
+	}
+}
+
+type throttle struct {
+	limiter   *Limiter
+	algorithm Algorithm
+	key       KeyFunc
+}
+
+// Throttle limits the named route group to maxAttempts requests per decay
+// period. It panics when maxAttempts or decay is not positive, or when a
+// token bucket is asked of a shared store.
+func Throttle(name string, maxAttempts int, decay time.Duration, options ...Option) func(http.Handler) http.Handler {
+	if maxAttempts < 1 || decay <= 0 {
+		panic(fmt.Sprintf("ratelimit: throttle %q needs positive attempts and decay, got %d per %s", name, maxAttempts, decay))
+	}
+	t := &throttle{key: ByUser}
+	for _, option := range options {
+		option(t)
+	}
+	if t.limiter != nil && t.algorithm != "" {
+		mustSupport(t.limiter.store, t.algorithm)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Resolve the limiter lazily so routes can be built before the cache is ready.
+			limiter := t.limiter
+			if limiter == nil {
+				limiter = Default()
+			}
+			if t.algorithm != "" {
+				limiter = limiter.Using(t.algorithm)
+			}
+
+			result := limiter.Hit(name+":"+t.key(r), maxAttempts, decay)
+
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+				http.Error(w, "Too Many Attempts", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
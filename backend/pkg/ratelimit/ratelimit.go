@@ -0,0 +1,451 @@
+// Package ratelimit - Cache-backed rate limiting for the Jazz framework
+package ratelimit
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"jazz/backend/pkg/cache"
+	"jazz/backend/pkg/logger"
+)
+
+// Algorithm selects how a Limiter counts hits for a key.
+type Algorithm string
+
+const (
+	// FixedWindow counts hits in consecutive windows of the decay duration.
+	FixedWindow Algorithm = "fixed_window"
+	// SlidingWindow weights the previous window's hits by how much of it still overlaps the current one.
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket refills maxAttempts tokens evenly over the decay duration.
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Result describes the state of a key after a hit or a check.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAt    time.Time
+}
+
+// bucket is the state stored in the cache for a single key.
+type bucket struct {
+	Hits     int     `json:"hits"`
+	Previous int     `json:"previous"`
+	Tokens   float64 `json:"tokens"`
+	Start    int64   `json:"start"`
+	Window   int64   `json:"window"`
+}
+
+// Limiter tracks attempts per key in any Cache implementation.
+//
+// In a process-local store every hit reads, updates and writes the key's
+// state back, serialized by the Limiter. Stores shared between processes
+// implement cache.Incrementer instead, and windows are counted there with
+// atomic increments, so instances sharing a store never let extra attempts
+// through. Token buckets need the read-modify-write and are refused on
+// shared stores.
+type Limiter struct {
+	store     cache.Cache
+	algorithm Algorithm
+	mu        *sync.Mutex
+	now       func() time.Time
+}
+
+var (
+	defaultLimiter *Limiter
+	defaultOnce    sync.Once
+)
+
+// NewLimiter creates a Limiter storing its counters in the given cache. It
+// panics when a token bucket is asked of a shared store.
+func NewLimiter(store cache.Cache, algorithm Algorithm) *Limiter {
+	if algorithm == "" {
+		algorithm = FixedWindow
+	}
+	mustSupport(store, algorithm)
+	return &Limiter{
+		store:     store,
+		algorithm: algorithm,
+		mu:        &sync.Mutex{},
+		now:       time.Now,
+	}
+}
+
+// Default returns the singleton Limiter backed by the application cache.
+func Default() *Limiter {
+	defaultOnce.Do(func() {
+		defaultLimiter = NewLimiter(cache.NewCacheManager(), FixedWindow)
+	})
+	return defaultLimiter
+}
+
+// Using returns a Limiter sharing the same store that counts with another
+// algorithm. It panics when a token bucket is asked of a shared store.
+func (l *Limiter) Using(algorithm Algorithm) *Limiter {
+	mustSupport(l.store, algorithm)
+	clone := *l
+	clone.algorithm = algorithm
+	return &clone
+}
+
+// Hit records an attempt for key and reports whether it was within the
+// limit. A maxAttempts below 1 allows nothing.
+func (l *Limiter) Hit(key string, maxAttempts int, decay time.Duration) Result {
+	if maxAttempts < 1 {
+		return denied(maxAttempts, decay, l.now())
+	}
+	if counter, ok := l.store.(cache.Incrementer); ok {
+		return l.count(counter, key, maxAttempts, decay)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b := l.load(key, decay, now)
+	result := l.evaluate(b, maxAttempts, now)
+	if !result.Allowed {
+		return result
+	}
+
+	switch l.algorithm {
+	case TokenBucket:
+		b.Tokens--
+	default:
+		b.Hits++
+	}
+	l.save(key, b)
+
+	// The hit itself was allowed even when it used up the last attempt.
+	result = l.evaluate(b, maxAttempts, now)
+	result.Allowed = true
+	return result
+}
+
+// Attempt runs callback unless key has exhausted its attempts, recording a hit when it runs.
+func (l *Limiter) Attempt(key string, maxAttempts int, decay time.Duration, callback func() error) (bool, error) {
+	if result := l.Hit(key, maxAttempts, decay); !result.Allowed {
+		return false, nil
+	}
+	return true, callback()
+}
+
+// TooManyAttempts reports whether key has no attempts left.
+func (l *Limiter) TooManyAttempts(key string, maxAttempts int) bool {
+	return !l.Check(key, maxAttempts).Allowed
+}
+
+// Remaining returns how many attempts key has left.
+func (l *Limiter) Remaining(key string, maxAttempts int) int {
+	return l.Check(key, maxAttempts).Remaining
+}
+
+// AvailableIn returns how long until key can make another attempt.
+func (l *Limiter) AvailableIn(key string, maxAttempts int) time.Duration {
+	return l.Check(key, maxAttempts).RetryAfter
+}
+
+// Check returns the current state of key without recording a hit.
+func (l *Limiter) Check(key string, maxAttempts int) Result {
+	if maxAttempts < 1 {
+		return denied(maxAttempts, 0, l.now())
+	}
+	if counter, ok := l.store.(cache.Incrementer); ok {
+		now := l.now()
+		return l.evaluate(l.counted(counter, key, now), maxAttempts, now)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	return l.evaluate(l.load(key, 0, now), maxAttempts, now)
+}
+
+// denied is the Result for a limit that allows no attempts, which would
+// otherwise refill token buckets at a rate of zero.
+func denied(maxAttempts int, decay time.Duration, now time.Time) Result {
+	if decay <= 0 {
+		decay = time.Minute
+	}
+	return Result{Limit: maxAttempts, RetryAfter: decay, ResetAt: now.Add(decay)}
+}
+
+// Clear resets the attempts recorded for key.
+func (l *Limiter) Clear(key string) {
+	if _, ok := l.store.(cache.Incrementer); ok {
+		window := l.window(key)
+		index := l.now().UnixNano() / int64(window)
+		for _, i := range []int64{index, index - 1} {
+			if err := l.store.Forget(windowKey(key, i)); err != nil {
+				logger.Logger.Errorw("Failed to clear rate limiter", "key", key, "error", err)
+			}
+		}
+	}
+	if err := l.store.Forget(cacheKey(key)); err != nil {
+		logger.Logger.Errorw("Failed to clear rate limiter", "key", key, "error", err)
+	}
+}
+
+// load fetches the bucket for key and rolls it forward to now.
+func (l *Limiter) load(key string, decay time.Duration, now time.Time) *bucket {
+	b := &bucket{}
+	value, err := l.store.Get(cacheKey(key))
+	if err != nil {
+		logger.Logger.Errorw("Failed to read rate limiter", "key", key, "error", err)
+	}
+	if value == nil || decodeBucket(value, b) != nil || b.Window <= 0 {
+		window := decay
+		if window <= 0 {
+			window = time.Minute
+		}
+		b = &bucket{Start: now.UnixNano(), Window: int64(window), Tokens: -1}
+	}
+	if decay > 0 {
+		b.Window = int64(decay)
+	}
+
+	elapsed := now.UnixNano() - b.Start
+	switch l.algorithm {
+	case TokenBucket:
+		// Tokens are refilled lazily in evaluate from the time of the last refill.
+	case SlidingWindow:
+		if elapsed >= 2*b.Window {
+			b.Previous, b.Hits, b.Start = 0, 0, now.UnixNano()-elapsed%b.Window
+		} else if elapsed >= b.Window {
+			b.Previous, b.Hits, b.Start = b.Hits, 0, b.Start+b.Window
+		}
+	default:
+		if elapsed >= b.Window {
+			b.Hits, b.Start = 0, now.UnixNano()
+		}
+	}
+	return b
+}
+
+// evaluate computes the Result for b, refilling token buckets as a side effect.
+func (l *Limiter) evaluate(b *bucket, maxAttempts int, now time.Time) Result {
+	window := time.Duration(b.Window)
+	result := Result{Limit: maxAttempts}
+
+	switch l.algorithm {
+	case TokenBucket:
+		rate := float64(maxAttempts) / float64(window)
+		if b.Tokens < 0 {
+			b.Tokens = float64(maxAttempts)
+		}
+		b.Tokens = math.Min(float64(maxAttempts), b.Tokens+float64(now.UnixNano()-b.Start)*rate)
+		b.Start = now.UnixNano()
+		result.Remaining = int(b.Tokens)
+		result.ResetAt = now.Add(time.Duration((float64(maxAttempts) - b.Tokens) / rate))
+		if b.Tokens < 1 {
+			result.RetryAfter = time.Duration((1 - b.Tokens) / rate)
+		}
+	case SlidingWindow:
+		elapsed := float64(now.UnixNano()-b.Start) / float64(b.Window)
+		weighted := float64(b.Previous)*(1-elapsed) + float64(b.Hits)
+		result.Remaining = maxAttempts - int(math.Ceil(weighted))
+		result.ResetAt = time.Unix(0, b.Start).Add(window)
+		if result.Remaining <= 0 {
+			result.RetryAfter = slidingRetryAfter(b, maxAttempts, now)
+		}
+	default:
+		result.Remaining = maxAttempts - b.Hits
+		result.ResetAt = time.Unix(0, b.Start).Add(window)
+		if result.Remaining <= 0 {
+			result.RetryAfter = result.ResetAt.Sub(now)
+		}
+	}
+
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	result.Allowed = result.Remaining > 0
+	return result
+}
+
+// slidingRetryAfter returns how long until the weighted count of b drops below maxAttempts.
+func slidingRetryAfter(b *bucket, maxAttempts int, now time.Time) time.Duration {
+	window := time.Duration(b.Window)
+	start := time.Unix(0, b.Start)
+	previous, hits := b.Previous, b.Hits
+	if hits >= maxAttempts || previous == 0 {
+		// No slot frees up until the current hits have become the previous window.
+		start, previous, hits = start.Add(window), hits, 0
+	}
+	if previous == 0 || maxAttempts < 1 {
+		return start.Sub(now)
+	}
+
+	// Solve previous*(1-t) + hits <= maxAttempts-1 for the elapsed fraction t of the window.
+	fraction := 1 - float64(maxAttempts-1-hits)/float64(previous)
+	at := start.Add(time.Duration(fraction * float64(window)))
+	if at.Before(now) {
+		return 0
+	}
+	return at.Sub(now)
+}
+
+// save stores b for as long as it can influence future hits.
+func (l *Limiter) save(key string, b *bucket) {
+	ttl := l.ttl(time.Duration(b.Window))
+
+	payload, err := json.Marshal(b)
+	if err != nil {
+		logger.Logger.Errorw("Failed to serialize rate limiter", "key", key, "error", err)
+		return
+	}
+	if err := l.store.Set(cacheKey(key), string(payload), ttl); err != nil {
+		logger.Logger.Errorw("Failed to store rate limiter", "key", key, "error", err)
+	}
+}
+
+// count records a hit in the atomic counter of the current window, and
+// takes it back when it went over the limit.
+func (l *Limiter) count(counter cache.Incrementer, key string, maxAttempts int, decay time.Duration) Result {
+	now := l.now()
+	index := now.UnixNano() / int64(decay)
+	hits, err := counter.Increment(windowKey(key, index), 1, l.ttl(decay))
+	if err != nil {
+		logger.Logger.Errorw("Failed to count rate limiter hit", "key", key, "error", err)
+		hits = 1
+	}
+	if hits == 1 {
+		// The first hit of a window records its length for Check and Clear.
+		l.save(key, &bucket{Window: int64(decay)})
+	}
+
+	b := l.windowBucket(counter, key, index, decay)
+	b.Hits = int(hits) - 1
+	result := l.evaluate(b, maxAttempts, now)
+	if !result.Allowed {
+		if _, err := counter.Increment(windowKey(key, index), -1, l.ttl(decay)); err != nil {
+			logger.Logger.Errorw("Failed to take back rate limiter hit", "key", key, "error", err)
+		}
+		return result
+	}
+
+	b.Hits = int(hits)
+	result = l.evaluate(b, maxAttempts, now)
+	result.Allowed = true
+	return result
+}
+
+// windowBucket returns the bucket of the window at index, along with the
+// hits of the previous window when the algorithm weighs them.
+func (l *Limiter) windowBucket(counter cache.Incrementer, key string, index int64, window time.Duration) *bucket {
+	b := &bucket{Start: index * int64(window), Window: int64(window)}
+	if l.algorithm == SlidingWindow {
+		previous, err := counter.Increment(windowKey(key, index-1), 0, l.ttl(window))
+		if err != nil {
+			logger.Logger.Errorw("Failed to read rate limiter", "key", key, "error", err)
+		}
+		b.Previous = int(previous)
+	}
+	return b
+}
+
+// counted returns the bucket of the current window of key without
+// recording a hit.
+func (l *Limiter) counted(counter cache.Incrementer, key string, now time.Time) *bucket {
+	window := l.window(key)
+	index := now.UnixNano() / int64(window)
+	b := l.windowBucket(counter, key, index, window)
+
+	hits, err := counter.Increment(windowKey(key, index), 0, l.ttl(window))
+	if err != nil {
+		logger.Logger.Errorw("Failed to read rate limiter", "key", key, "error", err)
+	}
+	b.Hits = int(hits)
+	return b
+}
+
+// window returns the window length recorded for key, a minute when none is.
+func (l *Limiter) window(key string) time.Duration {
+	b := &bucket{}
+	value, err := l.store.Get(cacheKey(key))
+	if err != nil {
+		logger.Logger.Errorw("Failed to read rate limiter", "key", key, "error", err)
+	}
+	if value == nil || decodeBucket(value, b) != nil || b.Window <= 0 {
+		return time.Minute
+	}
+	return time.Duration(b.Window)
+}
+
+// ttl returns how long the state of a window can influence future hits.
+func (l *Limiter) ttl(window time.Duration) time.Duration {
+	if l.algorithm == SlidingWindow {
+		window *= 2
+	}
+	return window + time.Second
+}
+
+// mustSupport panics when store cannot count algorithm atomically.
+func mustSupport(store cache.Cache, algorithm Algorithm) {
+	if _, ok := store.(cache.Incrementer); ok && algorithm == TokenBucket {
+		panic("ratelimit: token buckets need a process-local store, use a fixed or sliding window with a shared one")
+	}
+}
+
+// windowKey returns the key of the atomic counter of a window.
+func windowKey(key string, index int64) string {
+	return cacheKey(key) + ":" + strconv.FormatInt(index, 10)
+}
+
+// cacheKey maps an arbitrary limiter key to one that every cache driver accepts.
+func cacheKey(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return "ratelimit_" + hex.EncodeToString(sum[:])
+}
+
+// decodeBucket reads a bucket back from whatever representation the cache driver returned.
+func decodeBucket(value interface{}, b *bucket) error {
+	raw, ok := value.(string)
+	if !ok {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		raw = string(encoded)
+	}
+
+	if err := json.Unmarshal([]byte(raw), b); err == nil {
+		return nil
+	}
+
+	// Drivers returning the raw stored bytes hand back the JSON-encoded string.
+	var inner string
+	if err := json.Unmarshal([]byte(raw), &inner); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(inner), b)
+}
+
+// Hit records an attempt for key using the default Limiter.
+func Hit(key string, maxAttempts int, decay time.Duration) Result {
+	return Default().Hit(key, maxAttempts, decay)
+}
+
+// Attempt runs callback through the default Limiter.
+func Attempt(key string, maxAttempts int, decay time.Duration, callback func() error) (bool, error) {
+	return Default().Attempt(key, maxAttempts, decay, callback)
+}
+
+// TooManyAttempts reports whether key has no attempts left in the default Limiter.
+func TooManyAttempts(key string, maxAttempts int) bool {
+	return Default().TooManyAttempts(key, maxAttempts)
+}
+
+// Clear resets the attempts recorded for key in the default Limiter.
+func Clear(key string) {
+	Default().Clear(key)
+}
@@ -0,0 +1,207 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"jazz/backend/pkg/cache"
+)
+
+// newTestLimiter returns a Limiter on an in-memory cache with a controllable clock.
+func newTestLimiter(algorithm Algorithm) (*Limiter, *time.Time) {
+	now := time.Now()
+	limiter := NewLimiter(cache.NewSwingCache(), algorithm)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+// sharedStore counts atomically like a store shared between processes.
+type sharedStore struct {
+	*cache.SwingCache
+	mu       sync.Mutex
+	counters map[string]int64
+}
+
+func newSharedStore() *sharedStore {
+	return &sharedStore{SwingCache: cache.NewSwingCache(), counters: map[string]int64{}}
+}
+
+func (s *sharedStore) Increment(key string, delta int64, expiration time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counters[key] += delta
+	return s.counters[key], nil
+}
+
+func (s *sharedStore) Forget(key string) error {
+	s.mu.Lock()
+	delete(s.counters, key)
+	s.mu.Unlock()
+	return s.SwingCache.Forget(key)
+}
+
+func TestFixedWindow(t *testing.T) {
+	limiter, now := newTestLimiter(FixedWindow)
+
+	for i := 0; i < 3; i++ {
+		if result := limiter.Hit("login", 3, time.Minute); !result.Allowed {
+			t.Fatalf("Expected hit %d to be allowed", i+1)
+		}
+	}
+	if result := limiter.Hit("login", 3, time.Minute); result.Allowed || result.RetryAfter != time.Minute {
+		t.Errorf("Expected fourth hit to be rejected for a minute, got %+v", result)
+	}
+	if !limiter.TooManyAttempts("login", 3) {
+		t.Error("Expected TooManyAttempts to report the exhausted key")
+	}
+
+	*now = now.Add(time.Minute)
+	if limiter.TooManyAttempts("login", 3) {
+		t.Error("Expected attempts to reset once the window elapsed")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	limiter, now := newTestLimiter(SlidingWindow)
+
+	for i := 0; i < 4; i++ {
+		limiter.Hit("api", 4, time.Minute)
+	}
+
+	// Half-way into the next window half of the previous hits still count.
+	*now = now.Add(90 * time.Second)
+	if remaining := limiter.Remaining("api", 4); remaining != 2 {
+		t.Errorf("Expected 2 remaining attempts, got %d", remaining)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	limiter, now := newTestLimiter(TokenBucket)
+
+	for i := 0; i < 2; i++ {
+		limiter.Hit("upload", 2, time.Minute)
+	}
+	if result := limiter.Hit("upload", 2, time.Minute); result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Errorf("Expected bucket to be empty for 30s, got %+v", result)
+	}
+
+	*now = now.Add(30 * time.Second)
+	if remaining := limiter.Remaining("upload", 2); remaining != 1 {
+		t.Errorf("Expected one token to be refilled, got %d", remaining)
+	}
+
+	if result := limiter.Hit("disabled", 0, time.Minute); result.Allowed || result.RetryAfter != time.Minute {
+		t.Errorf("Expected a limit of zero to allow nothing, got %+v", result)
+	}
+}
+
+func TestSharedStoreNeverOvershoots(t *testing.T) {
+	store := newSharedStore()
+	now := time.Unix(600, 0)
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		// Each Limiter stands for another instance with its own mutex.
+		limiter := NewLimiter(store, FixedWindow)
+		limiter.now = func() time.Time { return now }
+		for j := 0; j < 25; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if limiter.Hit("login", 10, time.Minute).Allowed {
+					allowed.Add(1)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	if allowed.Load() != 10 {
+		t.Errorf("Expected exactly 10 hits to be allowed, got %d", allowed.Load())
+	}
+
+	limiter := NewLimiter(store, SlidingWindow)
+	limiter.now = func() time.Time { return now.Add(90 * time.Second) }
+	if remaining := limiter.Remaining("login", 10); remaining != 5 {
+		t.Errorf("Expected half of the previous window to count, got %d remaining", remaining)
+	}
+
+	limiter.Clear("login")
+	if remaining := limiter.Remaining("login", 10); remaining != 10 {
+		t.Errorf("Expected Clear to reset the counters, got %d remaining", remaining)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a token bucket on a shared store to panic")
+		}
+	}()
+	limiter.Using(TokenBucket)
+}
+
+func TestAttemptAndClear(t *testing.T) {
+	limiter, _ := newTestLimiter(FixedWindow)
+
+	calls := 0
+	callback := func() error {
+		calls++
+		return nil
+	}
+
+	limiter.Attempt("send", 1, time.Minute, callback)
+	if ok, _ := limiter.Attempt("send", 1, time.Minute, callback); ok || calls != 1 {
+		t.Errorf("Expected second attempt to be throttled, ran callback %d times", calls)
+	}
+
+	limiter.Clear("send")
+	if ok, _ := limiter.Attempt("send", 1, time.Minute, callback); !ok || calls != 2 {
+		t.Error("Expected attempt to run after Clear")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	limiter, _ := newTestLimiter(FixedWindow)
+	handler := Throttle("login", 2, time.Minute, WithLimiter(limiter), WithKey(ByIP))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	var rr *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+	}
+
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status code %d but got %d", http.StatusTooManyRequests, rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "60" || rr.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Unexpected rate limit headers: %v", rr.Header())
+	}
+
+	// Another client keeps its own budget.
+	req, _ := http.NewRequest("POST", "/login", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("X-RateLimit-Remaining") != "1" {
+		t.Errorf("Expected a fresh budget for another IP, got %d %v", rr.Code, rr.Header())
+	}
+	if rr.Header().Get("X-RateLimit-Reset") == "" {
+		t.Error("Expected X-RateLimit-Reset on an allowed response")
+	}
+}
+
+func TestThrottleRejectsNonPositiveLimits(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected Throttle to panic on a limit of zero")
+		}
+	}()
+	Throttle("login", 0, time.Minute)
+}
@@ -0,0 +1,390 @@
+package schema
+
+import (
+	"strings"
+)
+
+// Column types.
+const (
+	typeBigIncrements = "bigIncrements"
+	typeIncrements    = "increments"
+	typeString        = "string"
+	typeText          = "text"
+	typeInteger       = "integer"
+	typeBigInteger    = "bigInteger"
+	typeSmallInteger  = "smallInteger"
+	typeBoolean       = "boolean"
+	typeDecimal       = "decimal"
+	typeFloat         = "float"
+	typeDouble        = "double"
+	typeDate          = "date"
+	typeDateTime      = "dateTime"
+	typeTimestamp     = "timestamp"
+	typeTime          = "time"
+	typeJSON          = "json"
+	typeUUID          = "uuid"
+	typeBinary        = "binary"
+)
+
+// Blueprint describes the columns, indexes and foreign keys of a table being
+// created or altered.
+type Blueprint struct {
+	table    string
+	creating bool
+	columns  []*ColumnDefinition
+	indexes  []*IndexDefinition
+	foreigns []*ForeignKeyDefinition
+
+	dropColumns  []string
+	renames      [][2]string
+	dropIndexes  []string
+	dropForeigns []string
+}
+
+// ColumnDefinition is a column of a Blueprint, refined by its modifiers.
+type ColumnDefinition struct {
+	blueprint     *Blueprint
+	name          string
+	kind          string
+	length        int
+	precision     int
+	scale         int
+	nullable      bool
+	unsigned      bool
+	autoIncrement bool
+	hasDefault    bool
+	defaultValue  interface{}
+	comment       string
+	after         string
+}
+
+// IndexDefinition is a plain, unique or primary index of a Blueprint.
+type IndexDefinition struct {
+	blueprint *Blueprint
+	kind      string
+	name      string
+	columns   []string
+}
+
+// ForeignKeyDefinition is a foreign key constraint of a Blueprint.
+type ForeignKeyDefinition struct {
+	name       string
+	columns    []string
+	on         string
+	references []string
+	onDelete   string
+	onUpdate   string
+}
+
+// Expression is a default value written to the DDL as is.
+type Expression string
+
+// Raw returns an expression, such as CURRENT_TIMESTAMP, for a default value.
+func Raw(sql string) Expression {
+	return Expression(sql)
+}
+
+func newBlueprint(table string, creating bool) *Blueprint {
+	return &Blueprint{table: table, creating: creating}
+}
+
+func (b *Blueprint) addColumn(kind, name string) *ColumnDefinition {
+	c := &ColumnDefinition{blueprint: b, name: name, kind: kind}
+	b.columns = append(b.columns, c)
+	return c
+}
+
+// ID adds an auto-incrementing big integer primary key named "id".
+func (b *Blueprint) ID() *ColumnDefinition {
+	return b.BigIncrements("id")
+}
+
+// BigIncrements adds an auto-incrementing big integer primary key.
+func (b *Blueprint) BigIncrements(name string) *ColumnDefinition {
+	c := b.addColumn(typeBigIncrements, name)
+	c.unsigned, c.autoIncrement = true, true
+	return c
+}
+
+// Increments adds an auto-incrementing integer primary key.
+func (b *Blueprint) Increments(name string) *ColumnDefinition {
+	c := b.addColumn(typeIncrements, name)
+	c.unsigned, c.autoIncrement = true, true
+	return c
+}
+
+// String adds a VARCHAR column, 255 characters long unless a length is given.
+func (b *Blueprint) String(name string, length ...int) *ColumnDefinition {
+	c := b.addColumn(typeString, name)
+	c.length = 255
+	if len(length) > 0 {
+		c.length = length[0]
+	}
+	return c
+}
+
+// Text adds a TEXT column.
+func (b *Blueprint) Text(name string) *ColumnDefinition {
+	return b.addColumn(typeText, name)
+}
+
+// Integer adds an INTEGER column.
+func (b *Blueprint) Integer(name string) *ColumnDefinition {
+	return b.addColumn(typeInteger, name)
+}
+
+// BigInteger adds a BIGINT column.
+func (b *Blueprint) BigInteger(name string) *ColumnDefinition {
+	return b.addColumn(typeBigInteger, name)
+}
+
+// SmallInteger adds a SMALLINT column.
+func (b *Blueprint) SmallInteger(name string) *ColumnDefinition {
+	return b.addColumn(typeSmallInteger, name)
+}
+
+// UnsignedBigInteger adds an unsigned BIGINT column.
+func (b *Blueprint) UnsignedBigInteger(name string) *ColumnDefinition {
+	return b.BigInteger(name).Unsigned()
+}
+
+// ForeignID adds an unsigned BIGINT column meant to reference another
+// table's ID. Chain Constrained to add the foreign key.
+func (b *Blueprint) ForeignID(name string) *ColumnDefinition {
+	return b.UnsignedBigInteger(name)
+}
+
+// Boolean adds a BOOLEAN column.
+func (b *Blueprint) Boolean(name string) *ColumnDefinition {
+	return b.addColumn(typeBoolean, name)
+}
+
+// Decimal adds a DECIMAL column with the given precision and scale.
+func (b *Blueprint) Decimal(name string, precision, scale int) *ColumnDefinition {
+	c := b.addColumn(typeDecimal, name)
+	c.precision, c.scale = precision, scale
+	return c
+}
+
+// Float adds a single precision floating point column.
+func (b *Blueprint) Float(name string) *ColumnDefinition {
+	return b.addColumn(typeFloat, name)
+}
+
+// Double adds a double precision floating point column.
+func (b *Blueprint) Double(name string) *ColumnDefinition {
+	return b.addColumn(typeDouble, name)
+}
+
+// Date adds a DATE column.
+func (b *Blueprint) Date(name string) *ColumnDefinition {
+	return b.addColumn(typeDate, name)
+}
+
+// DateTime adds a DATETIME column.
+func (b *Blueprint) DateTime(name string) *ColumnDefinition {
+	return b.addColumn(typeDateTime, name)
+}
+
+// Timestamp adds a TIMESTAMP column.
+func (b *Blueprint) Timestamp(name string) *ColumnDefinition {
+	return b.addColumn(typeTimestamp, name)
+}
+
+// Time adds a TIME column.
+func (b *Blueprint) Time(name string) *ColumnDefinition {
+	return b.addColumn(typeTime, name)
+}
+
+// JSON adds a JSON column.
+func (b *Blueprint) JSON(name string) *ColumnDefinition {
+	return b.addColumn(typeJSON, name)
+}
+
+// UUID adds a UUID column.
+func (b *Blueprint) UUID(name string) *ColumnDefinition {
+	return b.addColumn(typeUUID, name)
+}
+
+// Binary adds a BLOB column.
+func (b *Blueprint) Binary(name string) *ColumnDefinition {
+	return b.addColumn(typeBinary, name)
+}
+
+// Timestamps adds the nullable created_at and updated_at columns GORM fills in.
+func (b *Blueprint) Timestamps() {
+	b.Timestamp("created_at").Nullable()
+	b.Timestamp("updated_at").Nullable()
+}
+
+// SoftDeletes adds the nullable, indexed deleted_at column of gorm.DeletedAt.
+func (b *Blueprint) SoftDeletes() {
+	b.Timestamp("deleted_at").Nullable().Index()
+}
+
+// Index adds an index on columns.
+func (b *Blueprint) Index(columns ...string) *IndexDefinition {
+	return b.addIndex("index", columns)
+}
+
+// Unique adds a unique index on columns.
+func (b *Blueprint) Unique(columns ...string) *IndexDefinition {
+	return b.addIndex("unique", columns)
+}
+
+// Primary sets the primary key of a table being created.
+func (b *Blueprint) Primary(columns ...string) *IndexDefinition {
+	return b.addIndex("primary", columns)
+}
+
+func (b *Blueprint) addIndex(kind string, columns []string) *IndexDefinition {
+	index := &IndexDefinition{blueprint: b, kind: kind, columns: columns}
+	b.indexes = append(b.indexes, index)
+	return index
+}
+
+// Foreign adds a foreign key on columns. Chain References and On.
+func (b *Blueprint) Foreign(columns ...string) *ForeignKeyDefinition {
+	foreign := &ForeignKeyDefinition{name: b.indexName("foreign", columns), columns: columns, references: []string{"id"}}
+	b.foreigns = append(b.foreigns, foreign)
+	return foreign
+}
+
+// DropColumn drops columns of an existing table.
+func (b *Blueprint) DropColumn(names ...string) {
+	b.dropColumns = append(b.dropColumns, names...)
+}
+
+// RenameColumn renames a column of an existing table.
+func (b *Blueprint) RenameColumn(from, to string) {
+	b.renames = append(b.renames, [2]string{from, to})
+}
+
+// DropIndex drops a plain or unique index by name.
+func (b *Blueprint) DropIndex(name string) {
+	b.dropIndexes = append(b.dropIndexes, name)
+}
+
+// DropForeign drops a foreign key by name.
+func (b *Blueprint) DropForeign(name string) {
+	b.dropForeigns = append(b.dropForeigns, name)
+}
+
+// indexName follows Laravel's convention: users_email_unique.
+func (b *Blueprint) indexName(kind string, columns []string) string {
+	table := b.table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	name := strings.Join(append(append([]string{table}, columns...), kind), "_")
+	return strings.ToLower(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// Nullable allows NULL values.
+func (c *ColumnDefinition) Nullable() *ColumnDefinition {
+	c.nullable = true
+	return c
+}
+
+// Unsigned makes an integer column unsigned on MySQL.
+func (c *ColumnDefinition) Unsigned() *ColumnDefinition {
+	c.unsigned = true
+	return c
+}
+
+// Default sets the default value. Use Raw for SQL expressions.
+func (c *ColumnDefinition) Default(value interface{}) *ColumnDefinition {
+	c.hasDefault, c.defaultValue = true, value
+	return c
+}
+
+// UseCurrent defaults a date or time column to CURRENT_TIMESTAMP.
+func (c *ColumnDefinition) UseCurrent() *ColumnDefinition {
+	return c.Default(Raw("CURRENT_TIMESTAMP"))
+}
+
+// Comment documents the column, on MySQL and PostgreSQL.
+func (c *ColumnDefinition) Comment(comment string) *ColumnDefinition {
+	c.comment = comment
+	return c
+}
+
+// After places a column added to an existing table after another, on MySQL.
+func (c *ColumnDefinition) After(column string) *ColumnDefinition {
+	c.after = column
+	return c
+}
+
+// Unique adds a unique index on the column.
+func (c *ColumnDefinition) Unique() *ColumnDefinition {
+	c.blueprint.Unique(c.name)
+	return c
+}
+
+// Index adds an index on the column.
+func (c *ColumnDefinition) Index() *ColumnDefinition {
+	c.blueprint.Index(c.name)
+	return c
+}
+
+// Primary makes the column the primary key.
+func (c *ColumnDefinition) Primary() *ColumnDefinition {
+	c.blueprint.Primary(c.name)
+	return c
+}
+
+// Constrained adds a foreign key from the column to the id of table. The table
+// defaults to the column name without its _id suffix, pluralized with an "s".
+func (c *ColumnDefinition) Constrained(table ...string) *ForeignKeyDefinition {
+	on := strings.TrimSuffix(c.name, "_id") + "s"
+	if len(table) > 0 {
+		on = table[0]
+	}
+	return c.blueprint.Foreign(c.name).On(on)
+}
+
+// Name overrides the generated index name.
+func (i *IndexDefinition) Name(name string) *IndexDefinition {
+	i.name = name
+	return i
+}
+
+// References sets the referenced columns, id by default.
+func (f *ForeignKeyDefinition) References(columns ...string) *ForeignKeyDefinition {
+	f.references = columns
+	return f
+}
+
+// On sets the referenced table.
+func (f *ForeignKeyDefinition) On(table string) *ForeignKeyDefinition {
+	f.on = table
+	return f
+}
+
+// Name overrides the generated constraint name.
+func (f *ForeignKeyDefinition) Name(name string) *ForeignKeyDefinition {
+	f.name = name
+	return f
+}
+
+// OnDelete sets the referential action on delete, such as "cascade".
+func (f *ForeignKeyDefinition) OnDelete(action string) *ForeignKeyDefinition {
+	f.onDelete = action
+	return f
+}
+
+// OnUpdate sets the referential action on update.
+func (f *ForeignKeyDefinition) OnUpdate(action string) *ForeignKeyDefinition {
+	f.onUpdate = action
+	return f
+}
+
+// CascadeOnDelete deletes the referencing rows with the referenced one.
+func (f *ForeignKeyDefinition) CascadeOnDelete() *ForeignKeyDefinition {
+	return f.OnDelete("cascade")
+}
+
+// NullOnDelete sets the referencing columns to NULL when the referenced row is deleted.
+func (f *ForeignKeyDefinition) NullOnDelete() *ForeignKeyDefinition {
+	return f.OnDelete("set null")
+}
@@ -0,0 +1,372 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialects, named after their GORM dialector. MariaDB uses the mysql dialector.
+const (
+	SQLite    = "sqlite"
+	MySQL     = "mysql"
+	Postgres  = "postgres"
+	SQLServer = "sqlserver"
+)
+
+// grammar compiles blueprints to the DDL of one dialect.
+type grammar struct {
+	dialect string
+}
+
+func newGrammar(dialect string) (*grammar, error) {
+	switch dialect {
+	case SQLite, MySQL, Postgres, SQLServer:
+		return &grammar{dialect: dialect}, nil
+	default:
+		return nil, fmt.Errorf("the schema builder does not support the %s dialect", dialect)
+	}
+}
+
+// compile returns the statements that apply a blueprint, in order.
+func (g *grammar) compile(b *Blueprint) ([]string, error) {
+	var statements []string
+	if b.creating {
+		statements = append(statements, g.compileCreate(b))
+	} else {
+		alter, err := g.compileAlter(b)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, alter...)
+	}
+
+	for _, index := range b.indexes {
+		if index.kind == "primary" {
+			if b.creating {
+				continue
+			}
+			if g.dialect == SQLite {
+				return nil, fmt.Errorf("sqlite cannot add a primary key to the existing table %s", b.table)
+			}
+			statements = append(statements, fmt.Sprintf("alter table %s add primary key (%s)", g.wrapTable(b.table), g.columnize(index.columns)))
+			continue
+		}
+		unique := ""
+		if index.kind == "unique" {
+			unique = "unique "
+		}
+		statements = append(statements, fmt.Sprintf("create %sindex %s on %s (%s)",
+			unique, g.wrap(g.indexName(b, index)), g.wrapTable(b.table), g.columnize(index.columns)))
+	}
+
+	if g.dialect == Postgres {
+		for _, c := range b.columns {
+			if c.comment != "" {
+				statements = append(statements, fmt.Sprintf("comment on column %s.%s is %s", g.wrapTable(b.table), g.wrap(c.name), quote(c.comment)))
+			}
+		}
+	}
+	return statements, nil
+}
+
+// compileCreate returns the CREATE TABLE statement, with the primary key and
+// foreign keys inline as SQLite cannot add them later.
+func (g *grammar) compileCreate(b *Blueprint) string {
+	var definitions []string
+	for _, c := range b.columns {
+		definitions = append(definitions, g.compileColumn(c))
+	}
+	for _, index := range b.indexes {
+		if index.kind == "primary" {
+			definitions = append(definitions, fmt.Sprintf("primary key (%s)", g.columnize(index.columns)))
+		}
+	}
+	for _, foreign := range b.foreigns {
+		definitions = append(definitions, g.compileForeign(foreign))
+	}
+	return fmt.Sprintf("create table %s (%s)", g.wrapTable(b.table), strings.Join(definitions, ", "))
+}
+
+// compileAlter returns the statements altering an existing table.
+func (g *grammar) compileAlter(b *Blueprint) ([]string, error) {
+	table := g.wrapTable(b.table)
+	var statements []string
+
+	for _, c := range b.columns {
+		// SQL Server's ADD takes no COLUMN keyword
+		add := "add column"
+		if g.dialect == SQLServer {
+			add = "add"
+		}
+		statement := fmt.Sprintf("alter table %s %s %s", table, add, g.compileColumn(c))
+		if g.dialect == MySQL && c.after != "" {
+			statement += " after " + g.wrap(c.after)
+		}
+		statements = append(statements, statement)
+	}
+	for _, rename := range b.renames {
+		if g.dialect == SQLServer {
+			statements = append(statements, fmt.Sprintf("sp_rename %s, %s, N'COLUMN'", nquote(b.table+"."+rename[0]), nquote(rename[1])))
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s rename column %s to %s", table, g.wrap(rename[0]), g.wrap(rename[1])))
+	}
+	for _, name := range b.dropForeigns {
+		switch g.dialect {
+		case SQLite:
+			return nil, fmt.Errorf("sqlite cannot drop the foreign key %s of an existing table", name)
+		case MySQL:
+			statements = append(statements, fmt.Sprintf("alter table %s drop foreign key %s", table, g.wrap(name)))
+		default:
+			statements = append(statements, fmt.Sprintf("alter table %s drop constraint %s", table, g.wrap(name)))
+		}
+	}
+	for _, name := range b.dropIndexes {
+		if g.dialect == MySQL || g.dialect == SQLServer {
+			statements = append(statements, fmt.Sprintf("drop index %s on %s", g.wrap(name), table))
+		} else {
+			statements = append(statements, fmt.Sprintf("drop index %s", g.wrap(name)))
+		}
+	}
+	for _, name := range b.dropColumns {
+		statements = append(statements, fmt.Sprintf("alter table %s drop column %s", table, g.wrap(name)))
+	}
+	for _, foreign := range b.foreigns {
+		if g.dialect == SQLite {
+			return nil, fmt.Errorf("sqlite cannot add a foreign key to the existing table %s", b.table)
+		}
+		statements = append(statements, fmt.Sprintf("alter table %s add %s", table, g.compileForeign(foreign)))
+	}
+	return statements, nil
+}
+
+// compileColumn returns a column definition: name, type and modifiers.
+func (g *grammar) compileColumn(c *ColumnDefinition) string {
+	sql := g.wrap(c.name) + " " + g.typeOf(c)
+
+	if c.autoIncrement {
+		switch g.dialect {
+		case SQLite:
+			// Only an INTEGER PRIMARY KEY column auto-increments in SQLite
+			return sql + " primary key autoincrement not null"
+		case MySQL:
+			return sql + " not null auto_increment primary key" + g.compileComment(c)
+		case SQLServer:
+			return sql + " not null identity primary key"
+		default:
+			return sql + " primary key not null"
+		}
+	}
+
+	if c.nullable {
+		sql += " null"
+	} else {
+		sql += " not null"
+	}
+	if c.hasDefault {
+		sql += " default " + g.compileDefault(c.defaultValue)
+	}
+	return sql + g.compileComment(c)
+}
+
+// compileComment returns the inline comment MySQL supports.
+func (g *grammar) compileComment(c *ColumnDefinition) string {
+	if g.dialect == MySQL && c.comment != "" {
+		return " comment " + quote(c.comment)
+	}
+	return ""
+}
+
+// compileForeign returns a foreign key constraint definition.
+func (g *grammar) compileForeign(f *ForeignKeyDefinition) string {
+	sql := fmt.Sprintf("constraint %s foreign key (%s) references %s (%s)",
+		g.wrap(f.name), g.columnize(f.columns), g.wrapTable(f.on), g.columnize(f.references))
+	if f.onDelete != "" {
+		sql += " on delete " + f.onDelete
+	}
+	if f.onUpdate != "" {
+		sql += " on update " + f.onUpdate
+	}
+	return sql
+}
+
+// typeOf returns the SQL type of a column.
+func (g *grammar) typeOf(c *ColumnDefinition) string {
+	unsigned := ""
+	if g.dialect == MySQL && c.unsigned {
+		unsigned = " unsigned"
+	}
+
+	switch g.dialect {
+	case SQLite:
+		switch c.kind {
+		case typeBigIncrements, typeIncrements, typeInteger, typeBigInteger, typeSmallInteger:
+			return "integer"
+		case typeString, typeUUID:
+			return "varchar"
+		case typeText, typeJSON:
+			return "text"
+		case typeBoolean:
+			return "tinyint(1)"
+		case typeDecimal:
+			return "numeric"
+		case typeFloat, typeDouble:
+			return "float"
+		case typeTimestamp, typeDateTime:
+			return "datetime"
+		case typeBinary:
+			return "blob"
+		}
+	case MySQL:
+		switch c.kind {
+		case typeBigIncrements, typeBigInteger:
+			return "bigint" + unsigned
+		case typeIncrements, typeInteger:
+			return "int" + unsigned
+		case typeSmallInteger:
+			return "smallint" + unsigned
+		case typeString:
+			return fmt.Sprintf("varchar(%d)", c.length)
+		case typeUUID:
+			return "char(36)"
+		case typeBoolean:
+			return "tinyint(1)"
+		case typeDecimal:
+			return fmt.Sprintf("decimal(%d, %d)", c.precision, c.scale)
+		case typeBinary:
+			return "blob"
+		}
+	case SQLServer:
+		switch c.kind {
+		case typeBigIncrements, typeBigInteger:
+			return "bigint"
+		case typeIncrements, typeInteger:
+			return "int"
+		case typeSmallInteger:
+			return "smallint"
+		case typeString:
+			return fmt.Sprintf("nvarchar(%d)", c.length)
+		case typeText, typeJSON:
+			return "nvarchar(max)"
+		case typeUUID:
+			return "uniqueidentifier"
+		case typeBoolean:
+			return "bit"
+		case typeDecimal:
+			return fmt.Sprintf("decimal(%d, %d)", c.precision, c.scale)
+		case typeDouble:
+			return "float"
+		case typeDateTime, typeTimestamp:
+			return "datetime2(0)"
+		case typeBinary:
+			return "varbinary(max)"
+		}
+	case Postgres:
+		switch c.kind {
+		case typeBigIncrements:
+			return "bigserial"
+		case typeIncrements:
+			return "serial"
+		case typeBigInteger:
+			return "bigint"
+		case typeSmallInteger:
+			return "smallint"
+		case typeString:
+			return fmt.Sprintf("varchar(%d)", c.length)
+		case typeDecimal:
+			return fmt.Sprintf("decimal(%d, %d)", c.precision, c.scale)
+		case typeFloat:
+			return "real"
+		case typeDouble:
+			return "double precision"
+		case typeDateTime, typeTimestamp:
+			return "timestamp(0) without time zone"
+		case typeTime:
+			return "time(0) without time zone"
+		case typeBinary:
+			return "bytea"
+		}
+	}
+
+	// Types spelled the same way everywhere
+	switch c.kind {
+	case typeFloat:
+		return "float"
+	case typeDouble:
+		return "double"
+	case typeDateTime:
+		return "datetime"
+	default:
+		return strings.ToLower(c.kind)
+	}
+}
+
+// compileDefault returns the SQL literal of a default value.
+func (g *grammar) compileDefault(value interface{}) string {
+	switch v := value.(type) {
+	case Expression:
+		return string(v)
+	case bool:
+		if g.dialect == Postgres {
+			return strconv.FormatBool(v)
+		}
+		if v {
+			return "1"
+		}
+		return "0"
+	case string:
+		return quote(v)
+	case nil:
+		return "null"
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
+
+// indexName returns the name of an index, generating one when unnamed.
+func (g *grammar) indexName(b *Blueprint, index *IndexDefinition) string {
+	if index.name != "" {
+		return index.name
+	}
+	return b.indexName(index.kind, index.columns)
+}
+
+// wrapTable quotes a table name, which may be qualified by a schema.
+func (g *grammar) wrapTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = g.wrap(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// wrap quotes an identifier.
+func (g *grammar) wrap(name string) string {
+	switch g.dialect {
+	case MySQL:
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	case SQLServer:
+		return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// columnize quotes and joins column names.
+func (g *grammar) columnize(columns []string) string {
+	wrapped := make([]string, len(columns))
+	for i, column := range columns {
+		wrapped[i] = g.wrap(column)
+	}
+	return strings.Join(wrapped, ", ")
+}
+
+// quote returns a SQL string literal.
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// nquote returns a SQL Server Unicode string literal.
+func nquote(value string) string {
+	return "N" + quote(value)
+}
@@ -0,0 +1,104 @@
+// Package schema creates and alters tables from a fluent description, similar
+// to Laravel's schema builder, so migrations do not depend on the models.
+//
+//	schema.Create(tx, "users", func(t *schema.Blueprint) {
+//		t.ID()
+//		t.String("username").Unique()
+//		t.Timestamps()
+//		t.SoftDeletes()
+//	})
+package schema
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Create creates a table described by fn.
+func Create(db *gorm.DB, table string, fn func(t *Blueprint)) error {
+	return build(db, table, true, fn)
+}
+
+// Table alters an existing table: fn adds, renames or drops columns, indexes
+// and foreign keys.
+func Table(db *gorm.DB, table string, fn func(t *Blueprint)) error {
+	return build(db, table, false, fn)
+}
+
+// Drop drops a table.
+func Drop(db *gorm.DB, table string) error {
+	g, err := newGrammar(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	return exec(db, "drop table "+g.wrapTable(table))
+}
+
+// DropIfExists drops a table when it exists.
+func DropIfExists(db *gorm.DB, table string) error {
+	g, err := newGrammar(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	return exec(db, "drop table if exists "+g.wrapTable(table))
+}
+
+// Rename renames a table.
+func Rename(db *gorm.DB, from, to string) error {
+	g, err := newGrammar(db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+	switch g.dialect {
+	case MySQL:
+		return exec(db, fmt.Sprintf("rename table %s to %s", g.wrapTable(from), g.wrapTable(to)))
+	case SQLServer:
+		return exec(db, fmt.Sprintf("sp_rename %s, %s", nquote(from), nquote(to)))
+	}
+	return exec(db, fmt.Sprintf("alter table %s rename to %s", g.wrapTable(from), g.wrap(to)))
+}
+
+// HasTable reports whether a table exists.
+func HasTable(db *gorm.DB, table string) bool {
+	return db.Migrator().HasTable(table)
+}
+
+// HasColumn reports whether a table has a column.
+func HasColumn(db *gorm.DB, table, column string) bool {
+	return db.Migrator().HasColumn(table, column)
+}
+
+// toSQL returns the statements a Create or Table call would run for a
+// dialect, without running them.
+func toSQL(dialect, table string, creating bool, fn func(t *Blueprint)) ([]string, error) {
+	g, err := newGrammar(dialect)
+	if err != nil {
+		return nil, err
+	}
+	b := newBlueprint(table, creating)
+	fn(b)
+	return g.compile(b)
+}
+
+// build compiles a blueprint for the connection's dialect and runs it.
+func build(db *gorm.DB, table string, creating bool, fn func(t *Blueprint)) error {
+	statements, err := toSQL(db.Dialector.Name(), table, creating, fn)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if err := exec(db, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec runs one DDL statement.
+func exec(db *gorm.DB, statement string) error {
+	if err := db.Exec(statement).Error; err != nil {
+		return fmt.Errorf("%s: %w", statement, err)
+	}
+	return nil
+}
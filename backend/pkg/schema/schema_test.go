@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func createUsers(t *Blueprint) {
+	t.ID()
+	t.String("username").Unique()
+	t.Boolean("active").Default(true)
+	t.Timestamps()
+	t.SoftDeletes()
+}
+
+func TestCreateTableDDL(t *testing.T) {
+	tests := map[string][]string{
+		SQLite: {
+			`create table "users" ("id" integer primary key autoincrement not null, "username" varchar not null, "active" tinyint(1) not null default 1, "created_at" datetime null, "updated_at" datetime null, "deleted_at" datetime null)`,
+			`create unique index "users_username_unique" on "users" ("username")`,
+			`create index "users_deleted_at_index" on "users" ("deleted_at")`,
+		},
+		MySQL: {
+			"create table `users` (`id` bigint unsigned not null auto_increment primary key, `username` varchar(255) not null, `active` tinyint(1) not null default 1, `created_at` timestamp null, `updated_at` timestamp null, `deleted_at` timestamp null)",
+			"create unique index `users_username_unique` on `users` (`username`)",
+			"create index `users_deleted_at_index` on `users` (`deleted_at`)",
+		},
+		Postgres: {
+			`create table "users" ("id" bigserial primary key not null, "username" varchar(255) not null, "active" boolean not null default true, "created_at" timestamp(0) without time zone null, "updated_at" timestamp(0) without time zone null, "deleted_at" timestamp(0) without time zone null)`,
+			`create unique index "users_username_unique" on "users" ("username")`,
+			`create index "users_deleted_at_index" on "users" ("deleted_at")`,
+		},
+		SQLServer: {
+			`create table [users] ([id] bigint not null identity primary key, [username] nvarchar(255) not null, [active] bit not null default 1, [created_at] datetime2(0) null, [updated_at] datetime2(0) null, [deleted_at] datetime2(0) null)`,
+			`create unique index [users_username_unique] on [users] ([username])`,
+			`create index [users_deleted_at_index] on [users] ([deleted_at])`,
+		},
+	}
+
+	for dialect, want := range tests {
+		t.Run(dialect, func(t *testing.T) {
+			got, err := toSQL(dialect, "users", true, createUsers)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected\n%q\ngot\n%q", want, got)
+			}
+		})
+	}
+}
+
+func TestAlterTableDDL(t *testing.T) {
+	alter := func(t *Blueprint) {
+		t.ForeignID("team_id").Nullable().After("id").Constrained().NullOnDelete()
+		t.RenameColumn("username", "login")
+		t.DropIndex("users_username_unique")
+		t.DropColumn("active")
+	}
+
+	got, err := toSQL(MySQL, "users", false, alter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{
+		"alter table `users` add column `team_id` bigint unsigned null after `id`",
+		"alter table `users` rename column `username` to `login`",
+		"drop index `users_username_unique` on `users`",
+		"alter table `users` drop column `active`",
+		"alter table `users` add constraint `users_team_id_foreign` foreign key (`team_id`) references `teams` (`id`) on delete set null",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected\n%q\ngot\n%q", want, got)
+	}
+
+	if _, err := toSQL(SQLite, "users", false, alter); err == nil {
+		t.Error("Expected SQLite to refuse adding a foreign key to an existing table")
+	}
+	got, err = toSQL(SQLServer, "users", false, alter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = []string{
+		"alter table [users] add [team_id] bigint null",
+		"sp_rename N'users.username', N'login', N'COLUMN'",
+		"drop index [users_username_unique] on [users]",
+		"alter table [users] drop column [active]",
+		"alter table [users] add constraint [users_team_id_foreign] foreign key ([team_id]) references [teams] ([id]) on delete set null",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected\n%q\ngot\n%q", want, got)
+	}
+
+	if _, err := toSQL("oracle", "users", true, createUsers); err == nil {
+		t.Error("Expected an error for an unsupported dialect")
+	}
+}
+
+func TestBuilderRunsOnSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "schema.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	if err := Create(db, "users", createUsers); err != nil {
+		t.Fatalf("Failed to create users: %v", err)
+	}
+	err = Create(db, "posts", func(t *Blueprint) {
+		t.ID()
+		t.ForeignID("user_id").Constrained().CascadeOnDelete()
+		t.String("title", 100).Comment("Shown in listings")
+		t.Decimal("rating", 3, 1).Nullable()
+		t.Index("user_id", "title").Name("posts_by_user")
+	})
+	if err != nil {
+		t.Fatalf("Failed to create posts: %v", err)
+	}
+	if err := Table(db, "posts", func(t *Blueprint) { t.Text("body").Nullable() }); err != nil {
+		t.Fatalf("Failed to alter posts: %v", err)
+	}
+
+	if !HasColumn(db, "posts", "body") || !db.Migrator().HasIndex("posts", "posts_by_user") {
+		t.Error("Expected the column and index to exist")
+	}
+	if err := Rename(db, "posts", "articles"); err != nil || !HasTable(db, "articles") {
+		t.Errorf("Expected posts to be renamed: %v", err)
+	}
+	if err := DropIfExists(db, "articles"); err != nil || HasTable(db, "articles") {
+		t.Errorf("Expected articles to be dropped: %v", err)
+	}
+}
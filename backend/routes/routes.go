@@ -3,9 +3,11 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"jazz/backend/handlers"
 	"jazz/backend/pkg/middlewares"
+	"jazz/backend/pkg/ratelimit"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -13,10 +15,12 @@ import (
 // SetupRoutes sets up the application's routes.
 func SetupRoutes() http.Handler {
 	r := chi.NewRouter()
+	r.Use(middlewares.StickyWrites)
+	r.Use(middlewares.CountQueries)
 
 	// Public routes
-	r.Post("/register", handlers.RegisterUserHandler)
-	r.Post("/login", handlers.LoginHandler)
+	r.With(ratelimit.Throttle("register", 5, time.Minute, ratelimit.WithKey(ratelimit.ByIP))).Post("/register", handlers.RegisterUserHandler)
+	r.With(ratelimit.Throttle("login", 5, time.Minute, ratelimit.WithKey(ratelimit.ByIP))).Post("/login", handlers.LoginHandler)
 
 	// Protected routes
 	r.Group(func(r chi.Router) {